@@ -0,0 +1,136 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceSelector selects namespaces by their labels and names, a namespace
+// must match both the label selector and one of the names when they are set.
+// An empty NamespaceSelector selects every namespace.
+type NamespaceSelector struct {
+	// Selector is a label query over the namespaces.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Names is a list of glob patterns matched against the namespace name,
+	// e.g. "team-*".
+	// +optional
+	Names []string `json:"names,omitempty"`
+}
+
+// ClusterNamespaceLabelSpec defines the desired state of ClusterNamespaceLabel
+type ClusterNamespaceLabelSpec struct {
+
+	// NamespaceSelector selects the namespaces the labels are applied to,
+	// including namespaces created later on.
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector"`
+
+	// Labels consists of a collection of items known as labels, where each label is represented by a key-value pair.
+	// Values may be templates rendered for every namespace, like the labels of NamespaceLabels.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ConflictPolicy decides which value is applied when another NamespaceLabel
+	// or ClusterNamespaceLabel sets one of the labels to a different value.
+	// +kubebuilder:default=FirstWins
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// Priority of the labels when using the Priority conflict policy, the
+	// highest priority wins.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// NamespaceApplyStatus is the result of applying the labels to a single namespace.
+type NamespaceApplyStatus struct {
+	// Name of the namespace.
+	Name string `json:"name"`
+
+	// Applied is true when the namespace was updated with the labels this
+	// ClusterNamespaceLabel won.
+	Applied bool `json:"applied"`
+
+	// LastAppliedLabels are the labels applied to the namespace by this ClusterNamespaceLabel.
+	// +optional
+	LastAppliedLabels map[string]string `json:"lastAppliedLabels,omitempty"`
+
+	// RenderedLabels holds the values the templated labels were rendered to
+	// for the namespace.
+	// +optional
+	RenderedLabels map[string]string `json:"renderedLabels,omitempty"`
+
+	// Conflicts lists the labels that were lost to other NamespaceLabels or
+	// ClusterNamespaceLabels in the namespace.
+	// +optional
+	Conflicts []LabelConflict `json:"conflicts,omitempty"`
+
+	// Message describes why the labels could not be applied, or why some of
+	// them could not be rendered.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ClusterNamespaceLabelStatus defines the observed state of ClusterNamespaceLabel
+type ClusterNamespaceLabelStatus struct {
+
+	// Namespaces holds the result of applying the labels to every selected namespace.
+	// +optional
+	Namespaces []NamespaceApplyStatus `json:"namespaces,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that was last reconciled.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest observations of the ClusterNamespaceLabel state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Labels",type="string",JSONPath=".spec.labels",description="The labels of the namespaces"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether every label is applied to every selected namespace"
+// +kubebuilder:printcolumn:name="Conflicted",type="string",JSONPath=".status.conditions[?(@.type==\"Conflicted\")].status",description="Whether labels were lost to other NamespaceLabels"
+// +kubebuilder:printcolumn:name="Degraded",type="string",JSONPath=".status.conditions[?(@.type==\"Degraded\")].status",description="Whether the last reconciliation failed",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterNamespaceLabel is the Schema for the clusternamespacelabels API
+type ClusterNamespaceLabel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterNamespaceLabelSpec   `json:"spec,omitempty"`
+	Status ClusterNamespaceLabelStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterNamespaceLabelList contains a list of ClusterNamespaceLabel
+type ClusterNamespaceLabelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNamespaceLabel `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterNamespaceLabel{}, &ClusterNamespaceLabelList{})
+}
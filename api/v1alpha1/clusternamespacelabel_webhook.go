@@ -0,0 +1,142 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var clusternamespacelabellog = logf.Log.WithName("clusternamespacelabel-resource")
+
+// SetupWebhookWithManager registers the validating webhook of ClusterNamespaceLabels,
+// the kubernetes.io/ and namespacelabeler.dana.io/ prefixes are protected when
+// protectedLabels is nil. The denied requests are recorded by denials when set.
+func (r *ClusterNamespaceLabel) SetupWebhookWithManager(mgr ctrl.Manager, protectedLabels ProtectedLabels, denials DenialRecorder) error {
+	if protectedLabels == nil {
+		protectedLabels = prefixProtectedLabels(disallowedPrefixes)
+	}
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&ClusterNamespaceLabelValidator{ProtectedLabels: protectedLabels, Denials: denials}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-dana-io-dana-io-v1alpha1-clusternamespacelabel,mutating=false,failurePolicy=fail,sideEffects=None,groups=dana.io.dana.io,resources=clusternamespacelabels,verbs=create;update,versions=v1alpha1,name=vclusternamespacelabel.kb.io,admissionReviewVersions=v1
+
+// ClusterNamespaceLabelValidator validates the labels and the namespace
+// selector of ClusterNamespaceLabels.
+// +kubebuilder:object:generate=false
+type ClusterNamespaceLabelValidator struct {
+	// ProtectedLabels are the label keys ClusterNamespaceLabels are not
+	// allowed to set, the same as for NamespaceLabels.
+	ProtectedLabels ProtectedLabels
+
+	// Denials records the denied requests, they are not recorded when it is nil.
+	Denials DenialRecorder
+}
+
+var _ webhook.CustomValidator = &ClusterNamespaceLabelValidator{}
+
+// ValidateCreate implements webhook.CustomValidator to validate the creation of ClusterNamespaceLabel objects.
+// The labels are checked like the labels of NamespaceLabels, and the namespace
+// selector must parse.
+func (v *ClusterNamespaceLabelValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*ClusterNamespaceLabel)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterNamespaceLabel but got a %T", obj)
+	}
+	clusternamespacelabellog.Info("validate create", "name", r.Name)
+
+	return nil, v.validate(r)
+}
+
+// ValidateUpdate implements webhook.CustomValidator to validate the update of ClusterNamespaceLabel objects.
+// Only the updates changing the spec are validated, so the finalizer can
+// always be removed.
+func (v *ClusterNamespaceLabelValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*ClusterNamespaceLabel)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterNamespaceLabel but got a %T", newObj)
+	}
+	old, ok := oldObj.(*ClusterNamespaceLabel)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterNamespaceLabel but got a %T", oldObj)
+	}
+	clusternamespacelabellog.Info("validate update", "name", r.Name)
+
+	if !r.ObjectMeta.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(old.Spec, r.Spec) {
+		return nil, nil
+	}
+	return nil, v.validate(r)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ClusterNamespaceLabelValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks the labels against the label syntax and the protected
+// labels, and that the selector and the glob patterns of the names parse,
+// otherwise they would fail the merge of every namespace.
+func (v *ClusterNamespaceLabelValidator) validate(r *ClusterNamespaceLabel) error {
+	labelsValidator := &NamespaceLabelValidator{ProtectedLabels: v.ProtectedLabels}
+	allErrs := labelsValidator.validateLabels(r.Spec.Labels, field.NewPath("spec", "labels"))
+
+	denials := make(map[string]struct{})
+	for _, err := range allErrs {
+		if err.Type == field.ErrorTypeForbidden {
+			denials[DenialProtectedLabel] = struct{}{}
+		} else {
+			denials[DenialInvalidLabel] = struct{}{}
+		}
+	}
+
+	selectorPath := field.NewPath("spec", "namespaceSelector")
+	if selector := r.Spec.NamespaceSelector.Selector; selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(selectorPath.Child("selector"), selector.String(), err.Error()))
+			denials[DenialInvalidLabel] = struct{}{}
+		}
+	}
+	for i, pattern := range r.Spec.NamespaceSelector.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			allErrs = append(allErrs, field.Invalid(selectorPath.Child("names").Index(i), pattern, err.Error()))
+			denials[DenialInvalidLabel] = struct{}{}
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	recordDenials(v.Denials, "clusternamespacelabel", denials)
+	return apierrors.NewInvalid(GroupVersion.WithKind("ClusterNamespaceLabel").GroupKind(), r.Name, allErrs)
+}
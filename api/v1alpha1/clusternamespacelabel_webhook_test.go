@@ -0,0 +1,77 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ClusterNamespaceLabel Webhook", func() {
+	var clusterNamespaceLabel *ClusterNamespaceLabel
+
+	BeforeEach(func() {
+		clusterNamespaceLabel = &ClusterNamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "clusternamespacelabel-webhook-test"},
+			Spec: ClusterNamespaceLabelSpec{
+				NamespaceSelector: NamespaceSelector{Names: []string{"team-*"}},
+				Labels:            map[string]string{"team": "{{ .Namespace.Name }}"},
+			},
+		}
+	})
+
+	Context("when validating ClusterNamespaceLabel creation", func() {
+		It("should allow valid labels and selectors", func() {
+			Expect(k8sClient.Create(ctx, clusterNamespaceLabel)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, clusterNamespaceLabel)).Should(Succeed())
+		})
+
+		It("should check the labels like the labels of NamespaceLabels", func() {
+			clusterNamespaceLabel.Spec.Labels = map[string]string{
+				"kubernetes.io/team": "a",
+				"protected-key":      "a",
+				"env":                "not valid!",
+				"owner":              "{{ .Namespace.Unknown }}",
+			}
+
+			err := k8sClient.Create(ctx, clusterNamespaceLabel)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(SatisfyAll(
+				ContainSubstring("spec.labels[kubernetes.io/team]"),
+				ContainSubstring("spec.labels[protected-key]"),
+				ContainSubstring("spec.labels[env]"),
+				ContainSubstring("spec.labels[owner]"),
+			))
+		})
+
+		It("should reject selectors and names that do not parse", func() {
+			clusterNamespaceLabel.Spec.NamespaceSelector = NamespaceSelector{
+				Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "team", Operator: "Like"},
+				}},
+				Names: []string{"team-["},
+			}
+
+			err := k8sClient.Create(ctx, clusterNamespaceLabel)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(SatisfyAll(
+				ContainSubstring("spec.namespaceSelector.selector"),
+				ContainSubstring("spec.namespaceSelector.names[0]"),
+			))
+		})
+
+		It("should only validate the updates changing the spec", func() {
+			validator := &ClusterNamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels([]string{"team"})}
+
+			finalized := clusterNamespaceLabel.DeepCopy()
+			finalized.Finalizers = []string{"namespacelabeller.dana.io/finalizer"}
+			_, err := validator.ValidateUpdate(ctx, clusterNamespaceLabel, finalized)
+			Expect(err).NotTo(HaveOccurred())
+
+			finalized.Spec.Labels = map[string]string{"team": "a"}
+			_, err = validator.ValidateUpdate(ctx, clusterNamespaceLabel, finalized)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,115 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// KeysSubresource is the virtual subresource of NamespaceLabels whose
+	// names are the label keys, the users need the SetKeyVerb on it to set them.
+	KeysSubresource = "keys"
+
+	// SetKeyVerb is the verb authorizing users to set a label key, e.g.
+	//
+	//	- apiGroups: ["dana.io.dana.io"]
+	//	  resources: ["namespacelabels/keys"]
+	//	  resourceNames: ["network-zone"]
+	//	  verbs: ["set"]
+	SetKeyVerb = "set"
+)
+
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// KeyAuthorizer authorizes the users setting label keys with SubjectAccessReviews,
+// so the RBAC of the cluster decides which keys every user may set. Only the
+// inline labels can be authorized: the reconciliation refuses the keys needing
+// an authorization in the ConfigMaps and Secrets of labelsFrom, which change
+// without going through the webhook. The inherited labels were authorized in
+// the namespace of their NamespaceLabel.
+// +kubebuilder:object:generate=false
+type KeyAuthorizer struct {
+	// Client creates the SubjectAccessReviews.
+	Client client.Client
+
+	// Keys are glob patterns of the label keys needing an authorization, e.g.
+	// "network-zone" or "platform.dana.io/*", the other keys can be set by anyone.
+	Keys []string
+}
+
+// authorize returns the keys the user is not allowed to set in the namespace.
+func (a *KeyAuthorizer) authorize(ctx context.Context, user authenticationv1.UserInfo, namespace string, keys []string, fldPath *field.Path) (field.ErrorList, error) {
+	sort.Strings(keys)
+
+	allErrs := field.ErrorList{}
+	for _, key := range keys {
+		if !matchesAny(a.Keys, key) {
+			continue
+		}
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				UID:    user.UID,
+				Groups: user.Groups,
+				Extra:  extraValues(user.Extra),
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   namespace,
+					Verb:        SetKeyVerb,
+					Group:       GroupVersion.Group,
+					Resource:    "namespacelabels",
+					Subresource: KeysSubresource,
+					Name:        key,
+				},
+			},
+		}
+		if err := a.Client.Create(ctx, review); err != nil {
+			return nil, fmt.Errorf("unable to review the access to label key %q: %w", key, err)
+		}
+		if review.Status.Allowed {
+			continue
+		}
+
+		msg := fmt.Sprintf("user %q is not allowed to set the key, it needs the %q verb on namespacelabels/%s named %q",
+			user.Username, SetKeyVerb, KeysSubresource, key)
+		if review.Status.Reason != "" {
+			msg = fmt.Sprintf("%s: %s", msg, review.Status.Reason)
+		}
+		allErrs = append(allErrs, field.Forbidden(fldPath.Key(key), msg))
+	}
+	return allErrs, nil
+}
+
+// extraValues converts the extra information of an authenticated user to the
+// one of a SubjectAccessReview.
+func extraValues(extra map[string]authenticationv1.ExtraValue) map[string]authorizationv1.ExtraValue {
+	if extra == nil {
+		return nil
+	}
+	values := make(map[string]authorizationv1.ExtraValue, len(extra))
+	for key, value := range extra {
+		values[key] = authorizationv1.ExtraValue(value)
+	}
+	return values
+}
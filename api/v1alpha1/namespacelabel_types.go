@@ -17,29 +17,373 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// ConflictPolicy describes how a NamespaceLabel competes for a label key that
+// another NamespaceLabel in the same namespace sets to a different value.
+// +kubebuilder:validation:Enum=FirstWins;Priority;Reject
+type ConflictPolicy string
+
+const (
+	// FirstWinsConflictPolicy lets the oldest NamespaceLabel keep the key.
+	FirstWinsConflictPolicy ConflictPolicy = "FirstWins"
+
+	// PriorityConflictPolicy lets the NamespaceLabel with the highest priority
+	// keep the key, NamespaceLabels using any other policy count as priority 0
+	// and equal priorities fall back to FirstWins.
+	PriorityConflictPolicy ConflictPolicy = "Priority"
+
+	// RejectConflictPolicy never applies a key while another NamespaceLabel
+	// sets it to a different value.
+	RejectConflictPolicy ConflictPolicy = "Reject"
+)
+
+// Enforcement describes what happens when the labels on the namespace drift
+// from the labels of a NamespaceLabel.
+// +kubebuilder:validation:Enum=Enforce;Report;Ignore
+type Enforcement string
+
+const (
+	// EnforceEnforcement applies the labels and reverts any change made to them.
+	EnforceEnforcement Enforcement = "Enforce"
+
+	// ReportEnforcement never changes the namespace, the labels that differ
+	// from the namespace are only reported on the status.
+	ReportEnforcement Enforcement = "Report"
+
+	// IgnoreEnforcement applies the labels once for every generation of the
+	// spec, later changes made to them on the namespace are left alone.
+	IgnoreEnforcement Enforcement = "Ignore"
+)
+
+// OverridePolicy describes whether the descendant namespaces may set the
+// labels they inherit to other values.
+// +kubebuilder:validation:Enum=Allow;Deny
+type OverridePolicy string
+
+const (
+	// AllowOverridePolicy lets the labels set in a namespace override the
+	// labels it inherits, and nearer ancestors override farther ones.
+	AllowOverridePolicy OverridePolicy = "Allow"
+
+	// DenyOverridePolicy applies the inherited labels over the labels set in
+	// the descendant namespaces, which lose them as conflicts.
+	DenyOverridePolicy OverridePolicy = "Deny"
+)
+
+// PropagationKind is a kind of object in the namespace the labels are
+// propagated to.
+// +kubebuilder:validation:Enum=Pods;Deployments;StatefulSets;Services;PersistentVolumeClaims
+type PropagationKind string
+
+const (
+	// PodsPropagationKind labels the Pods, new ones as they are created.
+	PodsPropagationKind PropagationKind = "Pods"
+
+	// DeploymentsPropagationKind labels the Deployments.
+	DeploymentsPropagationKind PropagationKind = "Deployments"
+
+	// StatefulSetsPropagationKind labels the StatefulSets.
+	StatefulSetsPropagationKind PropagationKind = "StatefulSets"
+
+	// ServicesPropagationKind labels the Services.
+	ServicesPropagationKind PropagationKind = "Services"
+
+	// PersistentVolumeClaimsPropagationKind labels the PersistentVolumeClaims.
+	PersistentVolumeClaimsPropagationKind PropagationKind = "PersistentVolumeClaims"
+)
+
+// Condition types reported on the NamespaceLabel status.
+const (
+	// ConditionReady is true when every label of the spec is applied to the namespace.
+	ConditionReady = "Ready"
+
+	// ConditionApplied is true when the namespace was updated with the labels
+	// this NamespaceLabel won.
+	ConditionApplied = "Applied"
+
+	// ConditionConflicted is true when some of the labels were lost to other NamespaceLabels.
+	ConditionConflicted = "Conflicted"
+
+	// ConditionDegraded is true when the last reconciliation failed.
+	ConditionDegraded = "Degraded"
+
+	// ConditionDrifted is true when labels on the namespace differ from the
+	// labels of the spec, and the Report enforcement leaves them that way.
+	ConditionDrifted = "Drifted"
+
+	// ConditionSuspended is true when the reconciliation is suspended, either
+	// by the spec or for every object by the operator.
+	ConditionSuspended = "Suspended"
+
+	// ConditionActive is true when the time-bound labels are applied, and false
+	// before activeFrom or once expired.
+	ConditionActive = "Active"
+
+	// ConditionCompliant is false when the labels violate a NamespaceLabelPolicy
+	// selecting the namespace, for instance after the policy changed.
+	ConditionCompliant = "Compliant"
+
+	// ConditionNeedsAttention is true while a NamespaceLabel created with the
+	// default values of the required labels of its namespace still sets them.
+	ConditionNeedsAttention = "NeedsAttention"
+)
+
 // NamespaceLabelSpec defines the desired state of NamespaceLabel
 type NamespaceLabelSpec struct {
 
 	// Lables consists of a collection of items known as labels, where each label is represented by a key-value pair.
-	//
+	// Values may be templates rendered for the namespace, e.g. "{{ .Namespace.Name }}",
+	// `{{ index .Namespace.Annotations "owner" }}` or `{{ .Namespace.CreationTimestamp | date "2006-01" }}`.
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are applied to the namespace like the labels, with the same
+	// ownership, conflict policy and enforcement. Their values are used as they are.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LabelsFrom lists the ConfigMaps and Secrets of the namespace whose data
+	// entries are used as labels. Later sources override earlier ones, and the
+	// inline labels override them all.
+	// +optional
+	LabelsFrom []LabelsSource `json:"labelsFrom,omitempty"`
+
+	// ConflictPolicy decides which value is applied when another NamespaceLabel
+	// in the namespace sets one of the labels to a different value.
+	// +kubebuilder:default=FirstWins
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// Priority of the labels when using the Priority conflict policy, the
+	// highest priority wins.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Enforcement decides whether changes made to the labels on the namespace
+	// are reverted, only reported, or ignored.
+	// +kubebuilder:default=Enforce
+	// +optional
+	Enforcement Enforcement `json:"enforcement,omitempty"`
+
+	// Suspend pauses the reconciliation, the namespace is left as it is until
+	// it is unset. Deleting a suspended NamespaceLabel still removes its labels.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// ActiveFrom delays the labels and annotations until the given time.
+	// +optional
+	ActiveFrom *metav1.Time `json:"activeFrom,omitempty"`
+
+	// ExpiresAt removes the labels and annotations from the namespace at the
+	// given time.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// TTL removes the labels and annotations once they were active for the
+	// given duration, counted from activeFrom or else from the creation of the
+	// NamespaceLabel. The earliest of expiresAt and the TTL applies.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// Schedules only apply the labels and annotations within recurring
+	// windows, when any of the schedules is open.
+	// +optional
+	Schedules []LabelSchedule `json:"schedules,omitempty"`
+
+	// Inheritance propagates the labels to the descendants of the namespace,
+	// the namespaces whose parent label or annotation leads to it.
+	// +optional
+	Inheritance *LabelInheritance `json:"inheritance,omitempty"`
+
+	// PropagateTo copies the labels applied to the namespace onto the objects
+	// of the given kinds in it. New Pods are labelled on creation, and the
+	// existing objects by the reconciliation and a periodic sweep. Deployments
+	// and StatefulSets are labelled themselves, not their pod template.
+	// +optional
+	PropagateTo []LabelPropagation `json:"propagateTo,omitempty"`
+}
+
+// LabelPropagation copies labels of the namespace onto the objects of a kind.
+type LabelPropagation struct {
+	// Kind of the objects the labels are copied to.
+	Kind PropagationKind `json:"kind"`
+
+	// Keys selects the labels to copy, every label the NamespaceLabel applies
+	// to the namespace when empty.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+}
+
+// LabelInheritance describes which labels the descendant namespaces inherit.
+type LabelInheritance struct {
+	// Inherit is whether the labels are inherited, unless overridden for
+	// their key in keys.
+	// +kubebuilder:default=true
+	// +optional
+	Inherit bool `json:"inherit"`
+
+	// Keys overrides inherit for the given label keys, e.g. {"team": false}.
+	// +optional
+	Keys map[string]bool `json:"keys,omitempty"`
+
+	// Overrides decides whether the descendant namespaces may set the
+	// inherited labels to other values.
+	// +kubebuilder:default=Allow
+	// +optional
+	Overrides OverridePolicy `json:"overrides,omitempty"`
+}
+
+// LabelSchedule opens a window at every activation of a cron expression.
+type LabelSchedule struct {
+	// Cron is a standard cron expression of the start of the windows, e.g.
+	// "0 18 * * 5" for every Friday at 18:00.
+	Cron string `json:"cron"`
+
+	// Duration of every window, e.g. "60h".
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone the cron expression is evaluated in, e.g. "Europe/Paris". UTC
+	// is used when unset.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// TimeWindow is a period of time.
+type TimeWindow struct {
+	// Start of the window.
+	Start metav1.Time `json:"start"`
+
+	// End of the window.
+	End metav1.Time `json:"end"`
+}
+
+// LabelsSource references a ConfigMap or a Secret in the namespace of the
+// NamespaceLabel, exactly one of them must be set.
+type LabelsSource struct {
+	// ConfigMapRef references a ConfigMap whose data entries are used as labels.
+	// +optional
+	ConfigMapRef *corev1.LocalObjectReference `json:"configMapRef,omitempty"`
+
+	// SecretRef references a Secret whose data entries are used as labels.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Prefix only selects the data keys starting with it, the keys are used as
+	// label keys as they are.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Optional allows the referenced object to be missing, otherwise the labels
+	// of the namespace are not updated until it exists.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// LabelDrift describes a label that differs on the namespace from the spec.
+type LabelDrift struct {
+	// Key of the drifted label.
+	Key string `json:"key"`
+
+	// Value requested by this NamespaceLabel.
+	Value string `json:"value"`
+
+	// ObservedValue is the value found on the namespace.
+	// +optional
+	ObservedValue string `json:"observedValue,omitempty"`
+
+	// Missing is true when the namespace does not have the label at all.
+	// +optional
+	Missing bool `json:"missing,omitempty"`
+}
+
+// LabelConflict describes a label key that was lost to another NamespaceLabel.
+type LabelConflict struct {
+	// Key of the conflicting label.
+	Key string `json:"key"`
+
+	// Value requested by this NamespaceLabel.
+	Value string `json:"value"`
+
+	// Winner is the name of the NamespaceLabel whose value was applied, it is
+	// empty when no value was applied.
+	// +optional
+	Winner string `json:"winner,omitempty"`
+
+	// AppliedValue is the value applied to the namespace.
+	// +optional
+	AppliedValue string `json:"appliedValue,omitempty"`
 }
 
 // NamespaceLabelStatus defines the observed state of NamespaceLabel
 type NamespaceLabelStatus struct {
 
 	// LastAppliedLabels represents the last applied lables, it consists of the
-	// last state of the spec Labels field before the last change.
+	// labels of the spec that were applied to the namespace by this NamespaceLabel.
 	LastAppliedLabels map[string]string `json:"lastAppliedLabels,omitempty"`
+
+	// LastAppliedAnnotations are the annotations of the spec that were applied
+	// to the namespace by this NamespaceLabel.
+	// +optional
+	LastAppliedAnnotations map[string]string `json:"lastAppliedAnnotations,omitempty"`
+
+	// RenderedLabels holds the values the templated labels of the spec were
+	// rendered to for the namespace.
+	// +optional
+	RenderedLabels map[string]string `json:"renderedLabels,omitempty"`
+
+	// Conflicts lists the labels of the spec that were not applied because
+	// another NamespaceLabel won them.
+	// +optional
+	Conflicts []LabelConflict `json:"conflicts,omitempty"`
+
+	// Drift lists the labels of the spec that differ on the namespace, it is
+	// only reported with the Report enforcement.
+	// +optional
+	Drift []LabelDrift `json:"drift,omitempty"`
+
+	// NextTransition is the time the labels are next applied or removed, when
+	// the NamespaceLabel is time-bound.
+	// +optional
+	NextTransition *metav1.Time `json:"nextTransition,omitempty"`
+
+	// CurrentWindow is the window of the schedules the labels are applied in.
+	// +optional
+	CurrentWindow *TimeWindow `json:"currentWindow,omitempty"`
+
+	// PropagatedKinds are the kinds the labels were last propagated to, the
+	// objects of a kind are still cleaned up once the spec stops propagating to it.
+	// +optional
+	PropagatedKinds []PropagationKind `json:"propagatedKinds,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that was last reconciled.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest observations of the NamespaceLabel state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced
 // +kubebuilder:printcolumn:name="Labels",type="string",JSONPath=".spec.labels",description="The labels of the namespace"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether every label is applied"
+// +kubebuilder:printcolumn:name="Applied",type="string",JSONPath=".status.conditions[?(@.type==\"Applied\")].status",description="Whether the namespace was updated",priority=1
+// +kubebuilder:printcolumn:name="Conflicted",type="string",JSONPath=".status.conditions[?(@.type==\"Conflicted\")].status",description="Whether labels were lost to other NamespaceLabels"
+// +kubebuilder:printcolumn:name="Degraded",type="string",JSONPath=".status.conditions[?(@.type==\"Degraded\")].status",description="Whether the last reconciliation failed",priority=1
+// +kubebuilder:printcolumn:name="Enforcement",type="string",JSONPath=".spec.enforcement",description="Whether changes to the labels are reverted",priority=1
+// +kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".spec.suspend",description="Whether the reconciliation is suspended",priority=1
+// +kubebuilder:printcolumn:name="Drifted",type="string",JSONPath=".status.conditions[?(@.type==\"Drifted\")].status",description="Whether the namespace labels drifted from the spec",priority=1
+// +kubebuilder:printcolumn:name="Active",type="string",JSONPath=".status.conditions[?(@.type==\"Active\")].status",description="Whether the time-bound labels are applied",priority=1
+// +kubebuilder:printcolumn:name="Next Transition",type="date",JSONPath=".status.nextTransition",description="When the labels are next applied or removed",priority=1
+// +kubebuilder:printcolumn:name="Compliant",type="string",JSONPath=".status.conditions[?(@.type==\"Compliant\")].status",description="Whether the labels satisfy the NamespaceLabelPolicies",priority=1
+// +kubebuilder:printcolumn:name="Needs Attention",type="string",JSONPath=".status.conditions[?(@.type==\"NeedsAttention\")].status",description="Whether default values of required labels are still set",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // NamespaceLabel is the Schema for the namespacelabels API
 type NamespaceLabel struct {
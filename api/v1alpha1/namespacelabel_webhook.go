@@ -17,65 +17,597 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/robfig/cron/v3"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"dana.io/hello-world/pkg/template"
 )
 
-// list of disallowed prefixes
+// list of disallowed prefixes, used when no ProtectedLabels are configured.
+// The labels of the operator, such as the parent of a namespace, are set on
+// the namespaces themselves.
 var disallowedPrefixes = []string{
 	"kubernetes.io/",
+	"namespacelabeler.dana.io/",
+}
+
+// list of disallowed annotation prefixes, used when no ProtectedAnnotations
+// are configured. They hold the annotations of the operator and of kubectl.
+var disallowedAnnotationPrefixes = []string{
+	"namespacelabeler.dana.io/",
+	"kubectl.kubernetes.io/",
 }
 
+// ProtectedLabels decides which label keys NamespaceLabels are not allowed to set.
+// +kubebuilder:object:generate=false
+type ProtectedLabels interface {
+	// Protects returns whether the key is protected, and the prefix or key protecting it.
+	Protects(key string) (string, bool)
+}
+
+// prefixProtectedLabels protects every key starting with one of the prefixes.
+// +kubebuilder:object:generate=false
+type prefixProtectedLabels []string
+
+func (p prefixProtectedLabels) Protects(key string) (string, bool) {
+	for _, prefix := range p {
+		if strings.HasPrefix(key, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// Reasons of the webhook denials.
+const (
+	DenialInvalidLabel    = "InvalidLabel"
+	DenialProtectedLabel  = "ProtectedLabel"
+	DenialConflict        = "Conflict"
+	DenialManagedLabel    = "ManagedLabel"
+	DenialPolicy          = "Policy"
+	DenialUnauthorizedKey = "UnauthorizedKey"
+)
+
+// DenialRecorder records the requests denied by the webhooks, e.g. in a metric.
+// +kubebuilder:object:generate=false
+type DenialRecorder interface {
+	// RecordDenial records a request denied by the webhook for the reason.
+	RecordDenial(webhook, reason string)
+}
+
+// recordDenials records the reasons of a denied request, when there is a recorder.
+func recordDenials(recorder DenialRecorder, webhook string, reasons map[string]struct{}) {
+	if recorder == nil {
+		return
+	}
+	for reason := range reasons {
+		recorder.RecordDenial(webhook, reason)
+	}
+}
+
+// CreatedByAnnotation records the user that created the NamespaceLabel.
+const CreatedByAnnotation = "namespacelabel.dana.io/created-by"
+
 // log is for logging in this package.
 var namespacelabellog = logf.Log.WithName("namespacelabel-resource")
 
-func (r *NamespaceLabel) SetupWebhookWithManager(mgr ctrl.Manager) error {
+// WebhookOptions configures the NamespaceLabel webhooks.
+// +kubebuilder:object:generate=false
+type WebhookOptions struct {
+	// LowercaseLabels lowercases the keys and values of the labels.
+	LowercaseLabels bool
+
+	// DefaultLabels are added to every NamespaceLabel that does not set them.
+	DefaultLabels map[string]string
+
+	// ProtectedLabels are the label keys NamespaceLabels are not allowed to set,
+	// the kubernetes.io/ and namespacelabeler.dana.io/ prefixes are protected when unset.
+	ProtectedLabels ProtectedLabels
+
+	// ProtectedAnnotations are the annotation keys NamespaceLabels are not
+	// allowed to set, the operator and kubectl annotations are protected when unset.
+	ProtectedAnnotations ProtectedLabels
+
+	// AuthorizedKeys are glob patterns of the label keys only the users
+	// authorized by a SubjectAccessReview may set, see KeyAuthorizer.
+	AuthorizedKeys []string
+
+	// Denials records the requests denied by the validating webhook.
+	Denials DenialRecorder
+}
+
+func (r *NamespaceLabel) SetupWebhookWithManager(mgr ctrl.Manager, options WebhookOptions) error {
+	protectedLabels := options.ProtectedLabels
+	if protectedLabels == nil {
+		protectedLabels = prefixProtectedLabels(disallowedPrefixes)
+	}
+	protectedAnnotations := options.ProtectedAnnotations
+	if protectedAnnotations == nil {
+		protectedAnnotations = prefixProtectedLabels(disallowedAnnotationPrefixes)
+	}
+	var keyAuthorizer *KeyAuthorizer
+	if len(options.AuthorizedKeys) > 0 {
+		keyAuthorizer = &KeyAuthorizer{Client: mgr.GetClient(), Keys: options.AuthorizedKeys}
+	}
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithDefaulter(&NamespaceLabelDefaulter{
+			LowercaseLabels: options.LowercaseLabels,
+			DefaultLabels:   options.DefaultLabels,
+			decoder:         admission.NewDecoder(mgr.GetScheme()),
+		}).
+		WithValidator(&NamespaceLabelValidator{
+			Client:               mgr.GetClient(),
+			ProtectedLabels:      protectedLabels,
+			ProtectedAnnotations: protectedAnnotations,
+			KeyAuthorizer:        keyAuthorizer,
+			Denials:              options.Denials,
+		}).
 		Complete()
 }
 
+//+kubebuilder:webhook:path=/mutate-dana-io-dana-io-v1alpha1-namespacelabel,mutating=true,failurePolicy=fail,sideEffects=None,groups=dana.io.dana.io,resources=namespacelabels,verbs=create;update,versions=v1alpha1,name=mnamespacelabel.kb.io,admissionReviewVersions=v1
+
+// NamespaceLabelDefaulter brings every NamespaceLabel to its canonical form.
+// +kubebuilder:object:generate=false
+type NamespaceLabelDefaulter struct {
+	// LowercaseLabels lowercases the keys and values of the labels.
+	LowercaseLabels bool
+
+	// DefaultLabels are added to every NamespaceLabel that does not set them.
+	DefaultLabels map[string]string
+
+	decoder *admission.Decoder
+}
+
+var _ webhook.CustomDefaulter = &NamespaceLabelDefaulter{}
+
+// Default implements webhook.CustomDefaulter to normalize the labels of
+// NamespaceLabel objects, add the default labels and record who created them.
+func (d *NamespaceLabelDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	r, ok := obj.(*NamespaceLabel)
+	if !ok {
+		return fmt.Errorf("expected a NamespaceLabel but got a %T", obj)
+	}
+	namespacelabellog.Info("default", "name", r.Name)
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Normalize the labels in the order of their keys, so the first of the keys
+	// that normalize to the same key always wins
+	keys := make([]string, 0, len(r.Spec.Labels))
+	for key := range r.Spec.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labels := make(map[string]string, len(r.Spec.Labels)+len(d.DefaultLabels))
+	for _, key := range keys {
+		normalizedKey, normalizedValue := d.normalize(key), d.normalize(r.Spec.Labels[key])
+		if template.IsTemplate(r.Spec.Labels[key]) {
+			// lowercasing would break the field names of templates
+			normalizedValue = strings.TrimSpace(r.Spec.Labels[key])
+		}
+		if _, exists := labels[normalizedKey]; !exists {
+			labels[normalizedKey] = normalizedValue
+		}
+	}
+
+	for key, value := range d.DefaultLabels {
+		if _, exists := labels[key]; !exists {
+			labels[key] = value
+		}
+	}
+
+	if len(labels) > 0 {
+		r.Spec.Labels = labels
+	}
+
+	// The creator is taken from the admission request on creation, and can't be
+	// changed afterwards
+	createdBy := req.UserInfo.Username
+	if req.Operation == admissionv1.Update {
+		old := &NamespaceLabel{}
+		if err := d.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return err
+		}
+		createdBy = old.Annotations[CreatedByAnnotation]
+	}
+
+	if createdBy == "" {
+		delete(r.Annotations, CreatedByAnnotation)
+		return nil
+	}
+	if r.Annotations == nil {
+		r.Annotations = make(map[string]string)
+	}
+	r.Annotations[CreatedByAnnotation] = createdBy
+
+	return nil
+}
+
+// normalize trims the whitespace around a label key or value, and lowercases
+// it if configured to.
+func (d *NamespaceLabelDefaulter) normalize(s string) string {
+	s = strings.TrimSpace(s)
+	if d.LowercaseLabels {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
 //+kubebuilder:webhook:path=/validate-dana-io-dana-io-v1alpha1-namespacelabel,mutating=false,failurePolicy=fail,sideEffects=None,groups=dana.io.dana.io,resources=namespacelabels,verbs=create;update,versions=v1alpha1,name=vnamespacelabel.kb.io,admissionReviewVersions=v1
 
-var _ webhook.Validator = &NamespaceLabel{}
+// NamespaceLabelValidator validates the labels of NamespaceLabels.
+// +kubebuilder:object:generate=false
+type NamespaceLabelValidator struct {
+	// Client looks up the other NamespaceLabels of the namespace, conflicts
+	// are not checked when it is nil.
+	Client client.Reader
+
+	// ProtectedLabels are the label keys NamespaceLabels are not allowed to set.
+	ProtectedLabels ProtectedLabels
+
+	// ProtectedAnnotations are the annotation keys NamespaceLabels are not
+	// allowed to set, they are not checked when it is nil.
+	ProtectedAnnotations ProtectedLabels
+
+	// KeyAuthorizer authorizes the user of the request to set the label keys
+	// it adds, changes or removes, they are not authorized when it is nil.
+	KeyAuthorizer *KeyAuthorizer
+
+	// Denials records the denied requests, they are not recorded when it is nil.
+	Denials DenialRecorder
+}
+
+var _ webhook.CustomValidator = &NamespaceLabelValidator{}
 
-// ValidateCreate implements webhook.Validator to validate the creation of NamespaceLabel objects.
-// It checks if any label has a disallowed prefix and returns an error if so.
-func (r *NamespaceLabel) ValidateCreate() (admission.Warnings, error) {
+// ValidateCreate implements webhook.CustomValidator to validate the creation of NamespaceLabel objects.
+// It checks that every label is a valid Kubernetes label that is not protected,
+// and returns all the problems found at once.
+func (v *NamespaceLabelValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*NamespaceLabel)
+	if !ok {
+		return nil, fmt.Errorf("expected a NamespaceLabel but got a %T", obj)
+	}
 	namespacelabellog.Info("validate create", "name", r.Name)
 
-	// Iterating through all the labels in the spec
+	keys := make([]string, 0, len(r.Spec.Labels))
 	for key := range r.Spec.Labels {
-		// Check if the label key has any disallowed prefix
-		for _, prefix := range disallowedPrefixes {
-			if strings.HasPrefix(key, prefix) {
-				return nil, fmt.Errorf("label with key %q is not allowed to have the '%s' prefix", key, prefix)
+		keys = append(keys, key)
+	}
+
+	return v.validate(ctx, r, r.Spec.Labels, keys)
+}
+
+// ValidateUpdate implements webhook.CustomValidator to validate the update of NamespaceLabel objects.
+// The validation criteria are the same as on creation, but only the labels the
+// update adds or changes are checked for conflicts, so existing conflicts don't
+// block unrelated updates.
+func (v *NamespaceLabelValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*NamespaceLabel)
+	if !ok {
+		return nil, fmt.Errorf("expected a NamespaceLabel but got a %T", newObj)
+	}
+	old, ok := oldObj.(*NamespaceLabel)
+	if !ok {
+		return nil, fmt.Errorf("expected a NamespaceLabel but got a %T", oldObj)
+	}
+	namespacelabellog.Info("validate update", "name", r.Name)
+
+	// the finalizer of a NamespaceLabel being deleted, and the other metadata
+	// changes, must not be blocked by configuration or policies that changed
+	// since the spec was admitted
+	if !r.ObjectMeta.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(old.Spec, r.Spec) {
+		return nil, nil
+	}
+
+	changed := make(map[string]string)
+	var keys []string
+	for key, value := range r.Spec.Labels {
+		if oldValue, exists := old.Spec.Labels[key]; !exists || oldValue != value {
+			changed[key] = value
+			keys = append(keys, key)
+		}
+	}
+	// removing a key needs the same authorization as setting it
+	for key := range old.Spec.Labels {
+		if _, exists := r.Spec.Labels[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+
+	return v.validate(ctx, r, changed, keys)
+}
+
+// validate validates the labels of the NamespaceLabel, and checks the given
+// labels for conflicts with the other NamespaceLabels of the namespace.
+// Conflicts are returned as warnings, unless the NamespaceLabel uses the
+// Reject conflict policy in which case they are denied. The user of the
+// request must be authorized to set the changed keys.
+func (v *NamespaceLabelValidator) validate(ctx context.Context, r *NamespaceLabel, introduced map[string]string, changedKeys []string) (admission.Warnings, error) {
+	fldPath := field.NewPath("spec", "labels")
+	allErrs := v.validateLabels(r.Spec.Labels, fldPath)
+	allErrs = append(allErrs, v.validateAnnotations(r.Spec.Annotations, field.NewPath("spec", "annotations"))...)
+	allErrs = append(allErrs, validateActiveWindow(&r.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateLabelsFrom(r.Spec.LabelsFrom, field.NewPath("spec", "labelsFrom"))...)
+	allErrs = append(allErrs, validateInheritance(r.Spec.Inheritance, field.NewPath("spec", "inheritance"))...)
+	allErrs = append(allErrs, validatePropagateTo(r.Spec.PropagateTo, field.NewPath("spec", "propagateTo"))...)
+
+	// the label errors are either forbidden protected keys or invalid labels
+	denials := make(map[string]struct{})
+	for _, err := range allErrs {
+		if err.Type == field.ErrorTypeForbidden {
+			denials[DenialProtectedLabel] = struct{}{}
+		} else {
+			denials[DenialInvalidLabel] = struct{}{}
+		}
+	}
+
+	// the policies selecting the namespace are only checked with a client
+	if v.Client != nil {
+		policyErrs, err := checkPolicies(ctx, v.Client, r, introduced, fldPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(policyErrs) > 0 {
+			allErrs = append(allErrs, policyErrs...)
+			denials[DenialPolicy] = struct{}{}
+		}
+	}
+
+	if v.KeyAuthorizer != nil && len(changedKeys) > 0 {
+		req, err := admission.RequestFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		keyErrs, err := v.KeyAuthorizer.authorize(ctx, req.UserInfo, r.Namespace, changedKeys, fldPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyErrs) > 0 {
+			allErrs = append(allErrs, keyErrs...)
+			denials[DenialUnauthorizedKey] = struct{}{}
+		}
+	}
+
+	var warnings admission.Warnings
+	conflicts, err := v.findConflicts(ctx, r, introduced)
+	if err != nil {
+		return nil, err
+	}
+	for _, conflict := range conflicts {
+		msg := fmt.Sprintf("label %q is already set to %q by NamespaceLabel %q", conflict.Key, conflict.AppliedValue, conflict.Winner)
+		if r.Spec.ConflictPolicy == RejectConflictPolicy {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(conflict.Key), msg))
+			denials[DenialConflict] = struct{}{}
+		} else {
+			warnings = append(warnings, msg)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+
+	recordDenials(v.Denials, "namespacelabel", denials)
+
+	return warnings, apierrors.NewInvalid(GroupVersion.WithKind("NamespaceLabel").GroupKind(), r.Name, allErrs)
+}
+
+// findConflicts returns the given labels that other NamespaceLabels of the
+// namespace set to a different value, sorted by key and NamespaceLabel name.
+func (v *NamespaceLabelValidator) findConflicts(ctx context.Context, r *NamespaceLabel, labels map[string]string) ([]LabelConflict, error) {
+	if v.Client == nil || len(labels) == 0 {
+		return nil, nil
+	}
+
+	var namespaceLabelList NamespaceLabelList
+	if err := v.Client.List(ctx, &namespaceLabelList, client.InNamespace(r.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var conflicts []LabelConflict
+	for _, other := range namespaceLabelList.Items {
+		if other.Name == r.Name || !other.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for key, value := range labels {
+			if otherValue, exists := other.Spec.Labels[key]; exists && otherValue != value {
+				conflicts = append(conflicts, LabelConflict{
+					Key:          key,
+					Value:        value,
+					Winner:       other.Name,
+					AppliedValue: otherValue,
+				})
 			}
 		}
 	}
 
-	return nil, nil
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Key != conflicts[j].Key {
+			return conflicts[i].Key < conflicts[j].Key
+		}
+		return conflicts[i].Winner < conflicts[j].Winner
+	})
+	return conflicts, nil
 }
 
-// ValidateUpdate implements webhook.Validator to validate the update of NamespaceLabel objects.
-// It reuses the logic from ValidateCreate since the validation criteria are the same.
-func (r *NamespaceLabel) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
-	namespacelabellog.Info("validate update", "name", r.Name)
+// validateLabels checks every label key and value against the Kubernetes label
+// syntax and the protected labels, templated values are checked to render. The
+// labels are checked in the order of their keys so the errors are reported in a
+// stable order.
+func (v *NamespaceLabelValidator) validateLabels(labels map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := fldPath.Key(key)
 
-	// Reuse the validation logic from ValidateCreate
-	return r.ValidateCreate()
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(keyPath, key, msg))
+		}
+
+		// Check if the label key is protected by a prefix or by its exact key
+		if protectedBy, protected := v.ProtectedLabels.Protects(key); protected {
+			if protectedBy == key {
+				allErrs = append(allErrs, field.Forbidden(keyPath, "the label key is protected"))
+			} else {
+				allErrs = append(allErrs, field.Forbidden(keyPath, fmt.Sprintf("label keys are not allowed to have the '%s' prefix", protectedBy)))
+			}
+		}
+
+		// templated values are checked once rendered for the namespace
+		if template.IsTemplate(labels[key]) {
+			if err := template.Validate(labels[key]); err != nil {
+				allErrs = append(allErrs, field.Invalid(keyPath, labels[key], err.Error()))
+			}
+			continue
+		}
+		for _, msg := range validation.IsValidLabelValue(labels[key]) {
+			allErrs = append(allErrs, field.Invalid(keyPath, labels[key], msg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateAnnotations checks the annotation keys and their total size like the
+// API server does, and checks the keys against the protected annotations.
+func (v *NamespaceLabelValidator) validateAnnotations(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := apivalidation.ValidateAnnotations(annotations, fldPath)
+	if v.ProtectedAnnotations == nil {
+		return allErrs
+	}
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if protectedBy, protected := v.ProtectedAnnotations.Protects(key); protected {
+			if protectedBy == key {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Key(key), "the annotation key is protected"))
+			} else {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Key(key), fmt.Sprintf("annotation keys are not allowed to have the '%s' prefix", protectedBy)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateActiveWindow checks that the labels of a time-bound NamespaceLabel
+// can be active at all.
+func validateActiveWindow(spec *NamespaceLabelSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.TTL != nil && spec.TTL.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("ttl"), spec.TTL.Duration.String(), "must be positive"))
+	}
+	if spec.ActiveFrom != nil && spec.ExpiresAt != nil && !spec.ExpiresAt.After(spec.ActiveFrom.Time) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("expiresAt"), spec.ExpiresAt.String(), "must be after activeFrom"))
+	}
+	for i, schedule := range spec.Schedules {
+		schedulePath := fldPath.Child("schedules").Index(i)
+		if _, err := cron.ParseStandard(schedule.Cron); err != nil {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("cron"), schedule.Cron, err.Error()))
+		}
+		if schedule.Duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("duration"), schedule.Duration.Duration.String(), "must be positive"))
+		}
+		if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("timeZone"), schedule.TimeZone, "unknown time zone"))
+		}
+	}
+	return allErrs
+}
+
+// validateInheritance checks that the keys of the inheritance are label keys.
+func validateInheritance(inheritance *LabelInheritance, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if inheritance == nil {
+		return allErrs
+	}
+	keys := make([]string, 0, len(inheritance.Keys))
+	for key := range inheritance.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("keys").Key(key), key, msg))
+		}
+	}
+	return allErrs
+}
+
+// validatePropagateTo checks that every kind is propagated to once, and that
+// the selected keys are label keys.
+func validatePropagateTo(propagations []LabelPropagation, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	kinds := make(map[PropagationKind]struct{})
+	for i, propagation := range propagations {
+		if _, exists := kinds[propagation.Kind]; exists {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("kind"), propagation.Kind))
+		}
+		kinds[propagation.Kind] = struct{}{}
+		for j, key := range propagation.Keys {
+			for _, msg := range validation.IsQualifiedName(key) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("keys").Index(j), key, msg))
+			}
+		}
+	}
+	return allErrs
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *NamespaceLabel) ValidateDelete() (admission.Warnings, error) {
-	namespacelabellog.Info("validate delete", "name", r.Name)
+// validateLabelsFrom checks that every source references exactly one object,
+// the labels they provide are checked by the controller once read.
+func validateLabelsFrom(sources []LabelsSource, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, source := range sources {
+		switch {
+		case source.ConfigMapRef == nil && source.SecretRef == nil:
+			allErrs = append(allErrs, field.Required(fldPath.Index(i), "one of configMapRef or secretRef must be set"))
+		case source.ConfigMapRef != nil && source.SecretRef != nil:
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), source, "only one of configMapRef or secretRef may be set"))
+		case source.ConfigMapRef != nil && source.ConfigMapRef.Name == "":
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("configMapRef", "name"), ""))
+		case source.SecretRef != nil && source.SecretRef.Name == "":
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("secretRef", "name"), ""))
+		}
+	}
+	return allErrs
+}
 
-	// TODO(user): fill in your validation logic upon object deletion.
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *NamespaceLabelValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	return nil, nil
 }
@@ -1,10 +1,20 @@
 package v1alpha1
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 var _ = Describe("NamespaceLabel Webhook", func() {
@@ -57,6 +67,332 @@ var _ = Describe("NamespaceLabel Webhook", func() {
 			// Cleanup the created NamespaceLabel
 			Expect(k8sClient.Delete(ctx, namespaceLabel1)).Should(Succeed())
 		})
+
+		It("should prevent creation if a label key or value is not valid", func() {
+			namespaceLabel1.Spec.Labels = map[string]string{
+				"bad key!":                 "value",
+				"empty-name/":              "value",
+				"valid-key":                strings.Repeat("v", 64),
+				"kubernetes.io/some-label": "bad value!",
+			}
+
+			err := k8sClient.Create(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("should report every invalid label under spec.labels", func() {
+			namespaceLabel1.Spec.Labels = map[string]string{
+				"bad key!":  "value",
+				"valid-key": "bad value!",
+			}
+
+			validator := &NamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+			_, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+			statusErr, ok := err.(*apierrors.StatusError)
+			Expect(ok).To(BeTrue())
+			fields := []string{}
+			for _, cause := range statusErr.ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf("spec.labels[bad key!]", "spec.labels[valid-key]"))
+		})
+
+		It("should validate templated values by rendering them", func() {
+			validator := &NamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+
+			namespaceLabel1.Spec.Labels = map[string]string{
+				"name":    "{{ .Namespace.Name }}",
+				"created": `{{ .Namespace.CreationTimestamp | date "2006-01" }}`,
+			}
+			_, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaceLabel1.Spec.Labels = map[string]string{"name": "{{ .Namespace.Unknown }}"}
+			_, err = validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("should check the annotations against the protected annotation prefixes", func() {
+			validator := &NamespaceLabelValidator{
+				ProtectedLabels:      prefixProtectedLabels(disallowedPrefixes),
+				ProtectedAnnotations: prefixProtectedLabels(disallowedAnnotationPrefixes),
+			}
+
+			namespaceLabel1.Spec.Annotations = map[string]string{
+				"scheduler.alpha.kubernetes.io/node-selector": "tier=apps",
+				"cost-center": "1234 (apps)",
+			}
+			_, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaceLabel1.Spec.Annotations = map[string]string{
+				"namespacelabeler.dana.io/owners": "{}",
+				"bad key!":                        "value",
+			}
+			_, err = validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.annotations[namespacelabeler.dana.io/owners]"))
+			Expect(err.Error()).To(ContainSubstring(`"bad key!"`))
+		})
+
+		It("should require the labels of a time-bound NamespaceLabel to be active at some point", func() {
+			validator := &NamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+			activeFrom := metav1.NewTime(time.Now().Add(time.Hour))
+
+			namespaceLabel1.Spec.ActiveFrom = &activeFrom
+			namespaceLabel1.Spec.TTL = &metav1.Duration{Duration: time.Hour}
+			_, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+
+			expiresAt := metav1.NewTime(activeFrom.Add(-time.Minute))
+			namespaceLabel1.Spec.ExpiresAt = &expiresAt
+			namespaceLabel1.Spec.TTL = &metav1.Duration{Duration: -time.Hour}
+			_, err = validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.expiresAt"))
+			Expect(err.Error()).To(ContainSubstring("spec.ttl"))
+		})
+
+		It("should require the inheritance keys to be label keys", func() {
+			validator := &NamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+
+			namespaceLabel1.Spec.Inheritance = &LabelInheritance{Inherit: true, Keys: map[string]bool{"name": false}}
+			_, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaceLabel1.Spec.Inheritance.Keys["bad key!"] = true
+			_, err = validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.inheritance.keys[bad key!]"))
+		})
+
+		It("should propagate to every kind once", func() {
+			validator := &NamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+
+			namespaceLabel1.Spec.PropagateTo = []LabelPropagation{
+				{Kind: PodsPropagationKind, Keys: []string{"name"}},
+				{Kind: ServicesPropagationKind},
+			}
+			_, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaceLabel1.Spec.PropagateTo = append(namespaceLabel1.Spec.PropagateTo,
+				LabelPropagation{Kind: PodsPropagationKind, Keys: []string{"bad key!"}})
+			_, err = validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.propagateTo[2].kind"))
+			Expect(err.Error()).To(ContainSubstring("spec.propagateTo[2].keys[0]"))
+		})
+
+		It("should validate the schedules", func() {
+			validator := &NamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+
+			namespaceLabel1.Spec.Schedules = []LabelSchedule{
+				{Cron: "0 18 * * 5", Duration: metav1.Duration{Duration: 60 * time.Hour}, TimeZone: "Europe/Paris"},
+			}
+			_, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaceLabel1.Spec.Schedules = []LabelSchedule{
+				{Cron: "every friday", Duration: metav1.Duration{Duration: time.Hour}},
+				{Cron: "0 18 * * 5", TimeZone: "Mars/Olympus"},
+			}
+			_, err = validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.schedules[0].cron"))
+			Expect(err.Error()).To(ContainSubstring("spec.schedules[1].duration"))
+			Expect(err.Error()).To(ContainSubstring("spec.schedules[1].timeZone"))
+		})
+
+		It("should require every labelsFrom source to reference one object", func() {
+			validator := &NamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+			reference := &corev1.LocalObjectReference{Name: "team-labels"}
+
+			namespaceLabel1.Spec.LabelsFrom = []LabelsSource{{ConfigMapRef: reference}, {SecretRef: reference, Prefix: "team"}}
+			_, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaceLabel1.Spec.LabelsFrom = []LabelsSource{{}, {ConfigMapRef: reference, SecretRef: reference}}
+			_, err = validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.labelsFrom[0]"))
+			Expect(err.Error()).To(ContainSubstring("spec.labelsFrom[1]"))
+		})
+
+		It("should only validate the updates changing the spec", func() {
+			// the label was admitted before the prefix was protected
+			namespaceLabel1.Spec.Labels["kubernetes.io/team"] = "apps"
+			validator := &NamespaceLabelValidator{ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+
+			finalized := namespaceLabel1.DeepCopy()
+			finalized.Finalizers = []string{"namespacelabeller.dana.io/finalizer"}
+			_, err := validator.ValidateUpdate(ctx, namespaceLabel1, finalized)
+			Expect(err).NotTo(HaveOccurred())
+
+			deleted := finalized.DeepCopy()
+			deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			deleted.Finalizers = nil
+			deleted.Spec.Labels["other"] = "value"
+			_, err = validator.ValidateUpdate(ctx, finalized, deleted)
+			Expect(err).NotTo(HaveOccurred())
+
+			changed := namespaceLabel1.DeepCopy()
+			changed.Spec.Labels["other"] = "value"
+			_, err = validator.ValidateUpdate(ctx, namespaceLabel1, changed)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("should prevent creation if a label is protected by the configuration", func() {
+			for _, key := range []string{"protected-key", "k8s.io/some-label", "pod-security.kubernetes.io/enforce", "namespacelabeler.dana.io/parent"} {
+				namespaceLabel1.Spec.Labels = map[string]string{key: "value"}
+
+				err := k8sClient.Create(ctx, namespaceLabel1)
+				Expect(apierrors.IsInvalid(err)).To(BeTrue(), "label %q should be protected", key)
+			}
+		})
+	})
+
+	Context("when authorizing the label keys", func() {
+		var validator *NamespaceLabelValidator
+
+		// requestBy returns a context of an admission request made by the given user
+		requestBy := func(username string, groups ...string) context.Context {
+			return admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+				},
+			})
+		}
+
+		BeforeEach(func() {
+			validator = &NamespaceLabelValidator{
+				ProtectedLabels: prefixProtectedLabels(disallowedPrefixes),
+				KeyAuthorizer:   &KeyAuthorizer{Client: k8sClient, Keys: []string{"network-zone", "platform.dana.io/*"}},
+			}
+		})
+
+		It("should deny the users not allowed to set an authorized key", func() {
+			namespaceLabel1.Spec.Labels["network-zone"] = "dmz"
+
+			_, err := validator.ValidateCreate(requestBy("jane"), namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(SatisfyAll(ContainSubstring("spec.labels[network-zone]"), ContainSubstring("namespacelabels/keys")))
+		})
+
+		It("should let anyone set the other keys", func() {
+			_, err := validator.ValidateCreate(requestBy("jane"), namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should allow the users the RBAC authorizes", func() {
+			namespaceLabel1.Spec.Labels["platform.dana.io/tier"] = "gold"
+
+			_, err := validator.ValidateCreate(requestBy("admin", "system:masters"), namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should authorize the removal of a key too", func() {
+			namespaceLabel1.Spec.Labels["network-zone"] = "dmz"
+			updated := namespaceLabel1.DeepCopy()
+			delete(updated.Spec.Labels, "network-zone")
+
+			_, err := validator.ValidateUpdate(requestBy("jane"), namespaceLabel1, updated)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+			// the keys left as they are are not reviewed
+			updated = namespaceLabel1.DeepCopy()
+			updated.Spec.Labels["name"] = "renamed"
+			_, err = validator.ValidateUpdate(requestBy("jane"), namespaceLabel1, updated)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when validating conflicts with other NamespaceLabels", func() {
+		var namespaceLabel2 *NamespaceLabel
+
+		BeforeEach(func() {
+			namespaceLabel2 = &NamespaceLabel{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "namespacelabel-webhook-test2",
+					Namespace: "default",
+				},
+				Spec: NamespaceLabelSpec{
+					Labels: map[string]string{"team": "first"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, namespaceLabel2)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, namespaceLabel2)).Should(Succeed())
+		})
+
+		It("should warn about a key already set to another value", func() {
+			namespaceLabel1.Spec.Labels = map[string]string{"team": "second", "unique": "value"}
+
+			validator := &NamespaceLabelValidator{Client: k8sClient, ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+			warnings, err := validator.ValidateCreate(ctx, namespaceLabel1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(`label "team" is already set to "first" by NamespaceLabel "namespacelabel-webhook-test2"`))
+		})
+
+		It("should only warn about the keys an update changes", func() {
+			namespaceLabel1.Spec.Labels = map[string]string{"team": "second", "unique": "value"}
+			updated := namespaceLabel1.DeepCopy()
+			updated.Spec.Labels["unique"] = "changed"
+
+			validator := &NamespaceLabelValidator{Client: k8sClient, ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+			warnings, err := validator.ValidateUpdate(ctx, namespaceLabel1, updated)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should reject a conflicting key when using the Reject conflict policy", func() {
+			namespaceLabel1.Spec.Labels = map[string]string{"team": "second"}
+			namespaceLabel1.Spec.ConflictPolicy = RejectConflictPolicy
+
+			err := k8sClient.Create(ctx, namespaceLabel1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+
+	Context("when defaulting NamespaceLabel creation", func() {
+		It("should trim the labels, add the default labels and record the creator", func() {
+			namespaceLabel1.Spec.Labels = map[string]string{
+				" padded-key ": " padded-value ",
+			}
+
+			Expect(k8sClient.Create(ctx, namespaceLabel1)).Should(Succeed())
+
+			created := &NamespaceLabel{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel1), created)).Should(Succeed())
+			Expect(created.Spec.Labels).To(Equal(map[string]string{
+				"padded-key": "padded-value",
+				"managed-by": "namespacelabel-operator",
+			}))
+			Expect(created.Annotations).To(HaveKey(CreatedByAnnotation))
+			Expect(created.Annotations[CreatedByAnnotation]).NotTo(BeEmpty())
+
+			// Cleanup the created NamespaceLabel
+			Expect(k8sClient.Delete(ctx, created)).Should(Succeed())
+		})
+
+		It("should not let the creator be changed on update", func() {
+			Expect(k8sClient.Create(ctx, namespaceLabel1)).Should(Succeed())
+
+			created := &NamespaceLabel{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel1), created)).Should(Succeed())
+			creator := created.Annotations[CreatedByAnnotation]
+
+			created.Annotations[CreatedByAnnotation] = "someone-else"
+			Expect(k8sClient.Update(ctx, created)).Should(Succeed())
+			Expect(created.Annotations).To(HaveKeyWithValue(CreatedByAnnotation, creator))
+
+			// Cleanup the created NamespaceLabel
+			Expect(k8sClient.Delete(ctx, created)).Should(Succeed())
+		})
 	})
 
 })
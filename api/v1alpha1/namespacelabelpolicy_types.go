@@ -0,0 +1,134 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceLabelPolicySpec defines the labels the NamespaceLabels of the
+// selected namespaces may set. Every rule left empty allows anything.
+type NamespaceLabelPolicySpec struct {
+
+	// NamespaceSelector selects the namespaces whose NamespaceLabels the
+	// policy applies to.
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector"`
+
+	// AllowedKeys is a list of glob patterns of the label keys the
+	// NamespaceLabels may set, e.g. "example.com/*". As with the namespace
+	// names, "*" does not match "/".
+	// +optional
+	AllowedKeys []string `json:"allowedKeys,omitempty"`
+
+	// Values constrains the values of the labels whose key matches.
+	// +optional
+	Values []LabelValueConstraint `json:"values,omitempty"`
+
+	// RequiredKeys are the label keys the selected namespaces must carry, set
+	// by any of their NamespaceLabels. As the NamespaceLabels set them together
+	// they are checked by the reconciliation rather than on admission, the
+	// NamespaceLabels of a namespace missing one are not Compliant.
+	// +optional
+	RequiredKeys []string `json:"requiredKeys,omitempty"`
+
+	// MaxLabels is the maximum number of labels the NamespaceLabels of a
+	// selected namespace set together. NamespaceLabels introducing keys beyond
+	// it are denied.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxLabels *int32 `json:"maxLabels,omitempty"`
+
+	// Namespaces requires labels on the selected namespaces when they are
+	// created, rather than on their NamespaceLabels.
+	// +optional
+	Namespaces *NamespaceRequirements `json:"namespaces,omitempty"`
+}
+
+// NamespaceAdmissionMode decides what happens to a new namespace missing
+// required labels.
+// +kubebuilder:validation:Enum=Deny;Default
+type NamespaceAdmissionMode string
+
+const (
+	// DenyNamespaceAdmission denies the creation of the namespace.
+	DenyNamespaceAdmission NamespaceAdmissionMode = "Deny"
+
+	// DefaultNamespaceAdmission admits the namespace, and creates a
+	// NamespaceLabel in it setting the default values of the missing labels.
+	// The NamespaceLabel needs attention until these values are changed.
+	DefaultNamespaceAdmission NamespaceAdmissionMode = "Default"
+)
+
+// NamespaceRequirements are the labels the new namespaces must carry. The
+// namespaces created before the policy are left alone.
+type NamespaceRequirements struct {
+	// RequiredKeys are the label keys every new namespace must carry.
+	RequiredKeys []string `json:"requiredKeys"`
+
+	// Mode decides whether the namespaces missing a required key are denied,
+	// or admitted with the default values.
+	// +kubebuilder:default=Deny
+	// +optional
+	Mode NamespaceAdmissionMode `json:"mode,omitempty"`
+
+	// Defaults are the values of the missing required keys with the Default
+	// mode, every required key needs one.
+	// +optional
+	Defaults map[string]string `json:"defaults,omitempty"`
+}
+
+// LabelValueConstraint constrains the values of the labels whose key matches,
+// a value must be one of the enumerated values or match the pattern when they
+// are set.
+type LabelValueConstraint struct {
+	// Key is a glob pattern of the label keys constrained.
+	Key string `json:"key"`
+
+	// Values enumerates the allowed values.
+	// +optional
+	Values []string `json:"values,omitempty"`
+
+	// Pattern is a regular expression the whole value must match, e.g. "[0-9]{4}".
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Max Labels",type="integer",JSONPath=".spec.maxLabels",description="The maximum number of labels of a namespace"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// NamespaceLabelPolicy is the Schema for the namespacelabelpolicies API
+type NamespaceLabelPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NamespaceLabelPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NamespaceLabelPolicyList contains a list of NamespaceLabelPolicy
+type NamespaceLabelPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceLabelPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceLabelPolicy{}, &NamespaceLabelPolicyList{})
+}
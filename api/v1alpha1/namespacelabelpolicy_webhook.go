@@ -0,0 +1,346 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"dana.io/hello-world/pkg/template"
+)
+
+// log is for logging in this package.
+var namespacelabelpolicylog = logf.Log.WithName("namespacelabelpolicy-resource")
+
+func (r *NamespaceLabelPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&NamespaceLabelPolicyValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-dana-io-dana-io-v1alpha1-namespacelabelpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=dana.io.dana.io,resources=namespacelabelpolicies,verbs=create;update,versions=v1alpha1,name=vnamespacelabelpolicy.kb.io,admissionReviewVersions=v1
+
+// NamespaceLabelPolicyValidator validates the patterns of NamespaceLabelPolicies.
+// +kubebuilder:object:generate=false
+type NamespaceLabelPolicyValidator struct{}
+
+var _ webhook.CustomValidator = &NamespaceLabelPolicyValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *NamespaceLabelPolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*NamespaceLabelPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a NamespaceLabelPolicy but got a %T", obj)
+	}
+	namespacelabelpolicylog.Info("validate create", "name", r.Name)
+
+	return nil, v.validate(r)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *NamespaceLabelPolicyValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*NamespaceLabelPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a NamespaceLabelPolicy but got a %T", newObj)
+	}
+	namespacelabelpolicylog.Info("validate update", "name", r.Name)
+
+	return nil, v.validate(r)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *NamespaceLabelPolicyValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks that the glob patterns and regular expressions of the
+// policy compile, otherwise they would never match.
+func (v *NamespaceLabelPolicyValidator) validate(r *NamespaceLabelPolicy) error {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec")
+
+	for i, pattern := range r.Spec.NamespaceSelector.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespaceSelector", "names").Index(i), pattern, err.Error()))
+		}
+	}
+	for i, pattern := range r.Spec.AllowedKeys {
+		if _, err := path.Match(pattern, ""); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("allowedKeys").Index(i), pattern, err.Error()))
+		}
+	}
+	for i, constraint := range r.Spec.Values {
+		if _, err := path.Match(constraint.Key, ""); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("values").Index(i).Child("key"), constraint.Key, err.Error()))
+		}
+		if _, err := compileValuePattern(constraint.Pattern); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("values").Index(i).Child("pattern"), constraint.Pattern, err.Error()))
+		}
+	}
+	allErrs = append(allErrs, validateNamespaceRequirements(r.Spec.Namespaces, fldPath.Child("namespaces"))...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("NamespaceLabelPolicy").GroupKind(), r.Name, allErrs)
+}
+
+// validateNamespaceRequirements checks that the required keys are label keys,
+// and that with the Default mode each has a valid default value.
+func validateNamespaceRequirements(requirements *NamespaceRequirements, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if requirements == nil {
+		return allErrs
+	}
+	for i, key := range requirements.RequiredKeys {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("requiredKeys").Index(i), key, msg))
+		}
+		value, exists := requirements.Defaults[key]
+		if !exists {
+			if requirements.Mode == DefaultNamespaceAdmission {
+				allErrs = append(allErrs, field.Required(fldPath.Child("defaults").Key(key),
+					"every required key needs a default value with the Default mode"))
+			}
+			continue
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("defaults").Key(key), value, msg))
+		}
+	}
+	return allErrs
+}
+
+// MissingNamespaceLabels returns the sorted keys required on the new
+// namespaces that the namespace does not carry.
+func (r *NamespaceLabelPolicy) MissingNamespaceLabels(namespace *corev1.Namespace) []string {
+	if r.Spec.Namespaces == nil {
+		return nil
+	}
+	var missing []string
+	for _, key := range r.Spec.Namespaces.RequiredKeys {
+		if _, exists := namespace.ObjectMeta.Labels[key]; !exists {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Selects returns whether the policy applies to the NamespaceLabels of the namespace.
+func (r *NamespaceLabelPolicy) Selects(namespace *corev1.Namespace) (bool, error) {
+	return r.Spec.NamespaceSelector.Selects(namespace)
+}
+
+// Check returns the labels of a NamespaceLabel whose key or value violate the
+// policy. The values of templated labels are only known once rendered, so
+// they are not checked against the value constraints. The required keys and
+// the maximum number of labels apply to the namespace, see CheckNamespace.
+func (r *NamespaceLabelPolicy) Check(labels map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if len(r.Spec.AllowedKeys) > 0 && !matchesAny(r.Spec.AllowedKeys, key) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(key),
+				fmt.Sprintf("the key is not allowed by NamespaceLabelPolicy %q, allowed keys are %s", r.Name, strings.Join(r.Spec.AllowedKeys, ", "))))
+			continue
+		}
+		if template.IsTemplate(labels[key]) {
+			continue
+		}
+		for _, constraint := range r.Spec.Values {
+			if matched, _ := path.Match(constraint.Key, key); !matched {
+				continue
+			}
+			if msg := checkValue(constraint, labels[key]); msg != "" {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Key(key),
+					fmt.Sprintf("the value %q is not allowed by NamespaceLabelPolicy %q, %s", labels[key], r.Name, msg)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// CheckNamespace returns the violations of the required keys and of the
+// maximum number of labels by the namespace. The required keys may be set by
+// any NamespaceLabel or by hand, while only the managed labels, those the
+// NamespaceLabels of the namespace apply together, count towards the maximum.
+func (r *NamespaceLabelPolicy) CheckNamespace(namespace *corev1.Namespace, managed map[string]string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("metadata", "labels")
+
+	for _, key := range r.Spec.RequiredKeys {
+		_, exists := namespace.ObjectMeta.Labels[key]
+		if _, managedKey := managed[key]; !exists && !managedKey {
+			allErrs = append(allErrs, field.Required(fldPath.Key(key),
+				fmt.Sprintf("the key is required on namespace %s by NamespaceLabelPolicy %q", namespace.Name, r.Name)))
+		}
+	}
+
+	if r.Spec.MaxLabels != nil && len(managed) > int(*r.Spec.MaxLabels) {
+		allErrs = append(allErrs, field.TooMany(fldPath, len(managed), int(*r.Spec.MaxLabels)))
+	}
+	return allErrs
+}
+
+// checkValue returns why the value violates the constraint, empty when it
+// does not.
+func checkValue(constraint LabelValueConstraint, value string) string {
+	if len(constraint.Values) > 0 {
+		for _, allowed := range constraint.Values {
+			if value == allowed {
+				return ""
+			}
+		}
+		if constraint.Pattern == "" {
+			return fmt.Sprintf("allowed values are %s", strings.Join(constraint.Values, ", "))
+		}
+	}
+	if constraint.Pattern == "" {
+		return ""
+	}
+	pattern, err := compileValuePattern(constraint.Pattern)
+	if err != nil {
+		return fmt.Sprintf("its pattern is invalid: %v", err)
+	}
+	if !pattern.MatchString(value) {
+		return fmt.Sprintf("values must match %q", constraint.Pattern)
+	}
+	return ""
+}
+
+// compileValuePattern compiles a value pattern, anchored so the whole value
+// must match.
+func compileValuePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// matchesAny returns whether the key matches one of the glob patterns.
+func matchesAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPolicies returns the labels of a NamespaceLabel violating the policies
+// selecting the namespace. With the managed labels of the namespace, the
+// violations of the namespace are returned too.
+func CheckPolicies(policies []NamespaceLabelPolicy, namespace *corev1.Namespace, labels, managed map[string]string, fldPath *field.Path) (field.ErrorList, error) {
+	allErrs := field.ErrorList{}
+	for i := range policies {
+		policy := &policies[i]
+		selected, err := policy.Selects(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			continue
+		}
+		allErrs = append(allErrs, policy.Check(labels, fldPath)...)
+		if managed != nil {
+			allErrs = append(allErrs, policy.CheckNamespace(namespace, managed)...)
+		}
+	}
+	return allErrs, nil
+}
+
+// checkPolicies returns the labels of the NamespaceLabel that violate the
+// policies selecting its namespace. The required keys are left to the
+// reconciliation, as the NamespaceLabels of a namespace set them together,
+// but introducing keys is denied once the NamespaceLabels of the namespace set
+// more labels than allowed.
+func checkPolicies(ctx context.Context, c client.Reader, r *NamespaceLabel, introduced map[string]string, fldPath *field.Path) (field.ErrorList, error) {
+	var policyList NamespaceLabelPolicyList
+	if err := c.List(ctx, &policyList); err != nil {
+		return nil, err
+	}
+	if len(policyList.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: r.Namespace}, namespace); err != nil {
+		return nil, err
+	}
+	allErrs, err := CheckPolicies(policyList.Items, namespace, r.Spec.Labels, nil, fldPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaceLabelList NamespaceLabelList
+	if err := c.List(ctx, &namespaceLabelList, client.InNamespace(r.Namespace)); err != nil {
+		return nil, err
+	}
+	others := make(map[string]struct{})
+	for _, other := range namespaceLabelList.Items {
+		if other.Name == r.Name || !other.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for key := range other.Spec.Labels {
+			others[key] = struct{}{}
+		}
+	}
+	keys := len(others)
+	adds := false
+	for key := range r.Spec.Labels {
+		if _, exists := others[key]; !exists {
+			keys++
+			_, introducedKey := introduced[key]
+			adds = adds || introducedKey
+		}
+	}
+
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+		if !adds || policy.Spec.MaxLabels == nil || keys <= int(*policy.Spec.MaxLabels) {
+			continue
+		}
+		selected, err := policy.Selects(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if selected {
+			allErrs = append(allErrs, field.TooMany(fldPath, keys, int(*policy.Spec.MaxLabels)))
+		}
+	}
+	return allErrs, nil
+}
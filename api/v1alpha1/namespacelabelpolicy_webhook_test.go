@@ -0,0 +1,145 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("NamespaceLabelPolicy Webhook", func() {
+	var policy *NamespaceLabelPolicy
+
+	BeforeEach(func() {
+		maxLabels := int32(3)
+		policy = &NamespaceLabelPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "namespacelabelpolicy-webhook-test"},
+			Spec: NamespaceLabelPolicySpec{
+				NamespaceSelector: NamespaceSelector{Names: []string{"team-*"}},
+				AllowedKeys:       []string{"team", "env", "cost-*"},
+				Values: []LabelValueConstraint{
+					{Key: "env", Values: []string{"dev", "prod"}},
+					{Key: "cost-*", Pattern: "[0-9]+"},
+				},
+				RequiredKeys: []string{"team"},
+				MaxLabels:    &maxLabels,
+			},
+		}
+	})
+
+	Context("when checking labels against the policy", func() {
+		fldPath := field.NewPath("spec", "labels")
+
+		It("should allow labels satisfying the policy", func() {
+			Expect(policy.Check(map[string]string{"team": "a", "env": "dev", "cost-center": "42"}, fldPath)).To(BeEmpty())
+		})
+
+		It("should report every violation", func() {
+			errs := policy.Check(map[string]string{
+				"env":         "staging",
+				"cost-center": "x42",
+				"owner":       "me",
+				"cost-unit":   "1",
+			}, fldPath)
+			Expect(errs).To(ConsistOf(
+				HaveField("Field", `spec.labels[env]`),
+				HaveField("Field", `spec.labels[cost-center]`),
+				HaveField("Field", `spec.labels[owner]`),
+			))
+		})
+
+		It("should check the required keys and the maximum against the namespace", func() {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "team-a",
+				Labels: map[string]string{"kubernetes.io/metadata.name": "team-a"},
+			}}
+
+			// the labels of every NamespaceLabel of the namespace count together
+			Expect(policy.CheckNamespace(namespace, map[string]string{"env": "dev", "cost-center": "42"})).To(ConsistOf(
+				HaveField("Field", `metadata.labels[team]`),
+			))
+
+			namespace.Labels["team"] = "a"
+			Expect(policy.CheckNamespace(namespace, map[string]string{"env": "dev", "cost-center": "42"})).To(BeEmpty())
+			Expect(policy.CheckNamespace(namespace, map[string]string{"team": "a", "env": "dev", "cost-center": "42", "cost-unit": "1"})).To(ConsistOf(
+				HaveField("Type", field.ErrorTypeTooMany),
+			))
+		})
+
+		It("should not check the values of templated labels", func() {
+			Expect(policy.Check(map[string]string{"team": "a", "env": "{{ .Namespace.Name }}"}, fldPath)).To(BeEmpty())
+		})
+
+		It("should only apply to the selected namespaces", func() {
+			selected, err := policy.Selects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selected).To(BeTrue())
+			selected, err = policy.Selects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selected).To(BeFalse())
+		})
+	})
+
+	Context("when validating NamespaceLabelPolicy creation", func() {
+		It("should reject patterns that do not compile", func() {
+			policy.Spec.AllowedKeys = []string{"team-["}
+			policy.Spec.Values = []LabelValueConstraint{{Key: "env", Pattern: "(dev"}}
+
+			_, err := (&NamespaceLabelPolicyValidator{}).ValidateCreate(ctx, policy)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(SatisfyAll(ContainSubstring("spec.allowedKeys[0]"), ContainSubstring("spec.values[0].pattern")))
+		})
+
+		It("should require a default value for every key required with the Default mode", func() {
+			policy.Spec.Namespaces = &NamespaceRequirements{
+				RequiredKeys: []string{"team", "env"},
+				Mode:         DefaultNamespaceAdmission,
+				Defaults:     map[string]string{"team": "unassigned"},
+			}
+
+			_, err := (&NamespaceLabelPolicyValidator{}).ValidateCreate(ctx, policy)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.namespaces.defaults[env]"))
+
+			policy.Spec.Namespaces.Mode = DenyNamespaceAdmission
+			_, err = (&NamespaceLabelPolicyValidator{}).ValidateCreate(ctx, policy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject the NamespaceLabels violating the policy", func() {
+			policy.Spec.NamespaceSelector = NamespaceSelector{Names: []string{"default"}}
+			Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, policy)).Should(Succeed())
+			}()
+
+			namespaceLabel := &NamespaceLabel{
+				ObjectMeta: metav1.ObjectMeta{Name: "namespacelabelpolicy-webhook-test", Namespace: "default"},
+				Spec:       NamespaceLabelSpec{Labels: map[string]string{"team": "a", "env": "staging"}},
+			}
+			validator := &NamespaceLabelValidator{Client: k8sClient, ProtectedLabels: prefixProtectedLabels(disallowedPrefixes)}
+			Eventually(func() bool {
+				_, err := validator.ValidateCreate(ctx, namespaceLabel)
+				return apierrors.IsInvalid(err)
+			}, timeout, interval).Should(BeTrue())
+
+			// the NamespaceLabels admitted before the policy can still be finalized
+			finalized := namespaceLabel.DeepCopy()
+			finalized.Finalizers = []string{"namespacelabeller.dana.io/finalizer"}
+			_, err := validator.ValidateUpdate(ctx, namespaceLabel, finalized)
+			Expect(err).NotTo(HaveOccurred())
+
+			// the required keys are left to the reconciliation
+			namespaceLabel.Spec.Labels = map[string]string{"env": "prod"}
+			_, err = validator.ValidateCreate(ctx, namespaceLabel)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaceLabel.Spec.Labels = map[string]string{"team": "a", "env": "prod", "cost-center": "42", "cost-unit": "1"}
+			_, err = validator.ValidateCreate(ctx, namespaceLabel)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,54 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Selects returns whether the namespace matches the label selector and one of
+// the name glob patterns, a nil selector or an empty list of patterns matches everything.
+func (s *NamespaceSelector) Selects(namespace *corev1.Namespace) (bool, error) {
+	if s.Selector != nil {
+		labelSelector, err := metav1.LabelSelectorAsSelector(s.Selector)
+		if err != nil {
+			return false, err
+		}
+		if !labelSelector.Matches(labels.Set(namespace.ObjectMeta.Labels)) {
+			return false, nil
+		}
+	}
+
+	if len(s.Names) == 0 {
+		return true, nil
+	}
+
+	for _, pattern := range s.Names {
+		matched, err := path.Match(pattern, namespace.Name)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,38 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("NamespaceSelector", func() {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-a",
+			Labels: map[string]string{"tier": "apps"},
+		},
+	}
+
+	It("should select every namespace when empty", func() {
+		Expect((&NamespaceSelector{}).Selects(namespace)).To(BeTrue())
+	})
+
+	It("should require both the label selector and a name pattern to match", func() {
+		selector := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "apps"}}
+
+		Expect((&NamespaceSelector{Selector: selector, Names: []string{"team-*"}}).Selects(namespace)).To(BeTrue())
+		Expect((&NamespaceSelector{Selector: selector, Names: []string{"infra-*"}}).Selects(namespace)).To(BeFalse())
+		Expect((&NamespaceSelector{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "infra"}},
+			Names:    []string{"team-*"},
+		}).Selects(namespace)).To(BeFalse())
+	})
+
+	It("should fail on an invalid name pattern", func() {
+		_, err := (&NamespaceSelector{Names: []string{"team-["}}).Selects(namespace)
+		Expect(err).To(HaveOccurred())
+	})
+})
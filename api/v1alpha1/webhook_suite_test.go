@@ -37,6 +37,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"dana.io/hello-world/internal/config"
 )
 
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
@@ -48,6 +50,11 @@ var testEnv *envtest.Environment
 var ctx context.Context
 var cancel context.CancelFunc
 
+const (
+	timeout  = time.Second * 10
+	interval = time.Millisecond * 250
+)
+
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -99,7 +106,19 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = (&NamespaceLabel{}).SetupWebhookWithManager(mgr)
+	protectedLabels, err := config.NewProtectedLabels(config.DefaultProtectedPrefixes, []string{"protected-key"}, "")
+	Expect(err).NotTo(HaveOccurred())
+
+	err = (&NamespaceLabel{}).SetupWebhookWithManager(mgr, WebhookOptions{
+		DefaultLabels:   map[string]string{"managed-by": "namespacelabel-operator"},
+		ProtectedLabels: protectedLabels,
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	err = (&ClusterNamespaceLabel{}).SetupWebhookWithManager(mgr, protectedLabels, nil)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = (&NamespaceLabelPolicy{}).SetupWebhookWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:webhook
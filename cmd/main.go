@@ -17,13 +17,18 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -32,7 +37,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	danaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/config"
 	"dana.io/hello-world/internal/controller"
+	"dana.io/hello-world/internal/metrics"
+	"dana.io/hello-world/internal/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -52,11 +60,47 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var lowercaseLabels bool
+	var defaultLabels string
+	var protectedLabelPrefixes string
+	var protectedLabelKeys string
+	var protectedLabelsConfig string
+	var protectedAnnotationPrefixes string
+	var protectManagedLabels bool
+	var operatorUsername string
+	var pauseAll bool
+	var propagationSweepInterval time.Duration
+	var authorizedLabelKeys string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&lowercaseLabels, "lowercase-labels", false,
+		"Lowercase the keys and values of the labels of every NamespaceLabel.")
+	flag.StringVar(&defaultLabels, "default-labels", "",
+		"Comma separated key=value labels added to every NamespaceLabel that does not set them, e.g. managed-by=namespacelabel-operator.")
+	flag.StringVar(&protectedLabelPrefixes, "protected-label-prefixes", strings.Join(config.DefaultProtectedPrefixes, ","),
+		"Comma separated label key prefixes NamespaceLabels are not allowed to set.")
+	flag.StringVar(&protectedLabelKeys, "protected-label-keys", "",
+		"Comma separated label keys NamespaceLabels are not allowed to set.")
+	flag.StringVar(&protectedLabelsConfig, "protected-labels-config", "",
+		"Path of a YAML file with more protected label prefixes and keys, reloaded whenever it changes.")
+	flag.StringVar(&protectedAnnotationPrefixes, "protected-annotation-prefixes", strings.Join(config.DefaultProtectedAnnotationPrefixes, ","),
+		"Comma separated annotation key prefixes NamespaceLabels are not allowed to set.")
+	flag.BoolVar(&protectManagedLabels, "protect-managed-labels", false,
+		"Deny manual changes to the managed labels of every namespace, rather than only the namespaces labeled with "+
+			webhook.ProtectManagedLabelsLabel+"=true.")
+	flag.StringVar(&operatorUsername, "operator-username", "",
+		"The username of the operator, the only one allowed to change the managed labels of protected namespaces. "+
+			"Defaults to the service account named by the POD_NAMESPACE and SERVICE_ACCOUNT_NAME environment variables, "+
+			"the managed labels are not protected without it.")
+	flag.BoolVar(&pauseAll, "pause-all", false,
+		"Suspend the reconciliation of every NamespaceLabel and ClusterNamespaceLabel, leaving the namespaces as they are. Deleted objects still remove their labels.")
+	flag.DurationVar(&propagationSweepInterval, "propagation-sweep-interval", 10*time.Minute,
+		"The interval between two sweeps propagating the namespace labels to the objects selected by the propagateTo of the NamespaceLabels.")
+	flag.StringVar(&authorizedLabelKeys, "authorized-label-keys", "",
+		"Comma separated glob patterns of the label keys only the users allowed to \"set\" namespacelabels/keys named after the key may set, e.g. network-zone,platform.dana.io/*. The labelsFrom of the NamespaceLabels are not allowed to set them.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -65,6 +109,18 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// the namespace webhook denies the changes to the managed labels made by
+	// anyone but the operator, including the operator itself without its username
+	if operatorUsername == "" {
+		operatorUsername = serviceAccountUsername(os.Getenv("POD_NAMESPACE"), os.Getenv("SERVICE_ACCOUNT_NAME"))
+	}
+	if operatorUsername == "" && protectManagedLabels {
+		setupLog.Error(errors.New("unknown operator username"),
+			"--protect-managed-labels needs the username of the operator, set --operator-username, "+
+				"or the POD_NAMESPACE and SERVICE_ACCOUNT_NAME environment variables")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -89,19 +145,119 @@ func main() {
 		os.Exit(1)
 	}
 
+	if pauseAll {
+		setupLog.Info("the reconciliation of every NamespaceLabel and ClusterNamespaceLabel is paused")
+	}
+
+	protectedLabels, err := config.NewProtectedLabels(splitList(protectedLabelPrefixes), splitList(protectedLabelKeys), protectedLabelsConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to load the protected labels", "protected-labels-config", protectedLabelsConfig)
+		os.Exit(1)
+	}
+	if err = mgr.Add(protectedLabels); err != nil {
+		setupLog.Error(err, "unable to watch the protected labels", "protected-labels-config", protectedLabelsConfig)
+		os.Exit(1)
+	}
+
 	// Setting up NamespaceLabelReconciler
 	if err = (&controller.NamespaceLabelReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Recorder:        mgr.GetEventRecorderFor("namespacelabel-controller"),
+		ProtectedLabels: protectedLabels,
+		AuthorizedKeys:  splitList(authorizedLabelKeys),
+		PauseAll:        pauseAll,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NamespaceLabel")
 		os.Exit(1)
 	}
 
-	if err = (&danaiov1alpha1.NamespaceLabel{}).SetupWebhookWithManager(mgr); err != nil {
+	// Setting up ClusterNamespaceLabelReconciler
+	if err = (&controller.ClusterNamespaceLabelReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Recorder:        mgr.GetEventRecorderFor("clusternamespacelabel-controller"),
+		ProtectedLabels: protectedLabels,
+		AuthorizedKeys:  splitList(authorizedLabelKeys),
+		PauseAll:        pauseAll,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterNamespaceLabel")
+		os.Exit(1)
+	}
+	if err = (&controller.RequiredLabelsReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("requiredlabels-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RequiredLabels")
+		os.Exit(1)
+	}
+
+	if err = mgr.Add(&controller.PropagationSweeper{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Interval:  propagationSweepInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to create the propagation sweeper")
+		os.Exit(1)
+	}
+
+	defaultLabelsMap, err := labels.ConvertSelectorToLabelsMap(defaultLabels)
+	if err != nil {
+		setupLog.Error(err, "unable to parse the default labels", "default-labels", defaultLabels)
+		os.Exit(1)
+	}
+
+	protectedAnnotations, err := config.NewProtectedLabels(splitList(protectedAnnotationPrefixes), nil, "")
+	if err != nil {
+		setupLog.Error(err, "unable to load the protected annotations")
+		os.Exit(1)
+	}
+
+	if err = (&danaiov1alpha1.NamespaceLabel{}).SetupWebhookWithManager(mgr, danaiov1alpha1.WebhookOptions{
+		LowercaseLabels:      lowercaseLabels,
+		DefaultLabels:        defaultLabelsMap,
+		ProtectedLabels:      protectedLabels,
+		ProtectedAnnotations: protectedAnnotations,
+		AuthorizedKeys:       splitList(authorizedLabelKeys),
+		Denials:              metrics.WebhookDenialRecorder{},
+	}); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceLabel")
 		os.Exit(1)
 	}
+	if err = (&danaiov1alpha1.ClusterNamespaceLabel{}).SetupWebhookWithManager(mgr, protectedLabels, metrics.WebhookDenialRecorder{}); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ClusterNamespaceLabel")
+		os.Exit(1)
+	}
+	if err = (&danaiov1alpha1.NamespaceLabelPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceLabelPolicy")
+		os.Exit(1)
+	}
+
+	// the namespaces opting in to the protection are left unprotected rather than
+	// keeping the operator from updating them
+	if operatorUsername == "" {
+		setupLog.Info("the operator username is unknown, the managed labels of the namespaces are not protected")
+	} else if err = webhook.SetupNamespaceWebhookWithManager(mgr, &webhook.NamespaceValidator{
+		OperatorUsername:     operatorUsername,
+		ProtectAllNamespaces: protectManagedLabels,
+	}); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Namespace")
+		os.Exit(1)
+	}
+	if err = webhook.SetupRequiredLabelsWebhookWithManager(mgr, &webhook.RequiredLabelsValidator{
+		Client: mgr.GetClient(),
+	}); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "RequiredLabels")
+		os.Exit(1)
+	}
+
+	if err = webhook.SetupPodWebhookWithManager(mgr, &webhook.PodLabeler{
+		Client: mgr.GetClient(),
+	}); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -119,3 +275,23 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// serviceAccountUsername returns the username of the service account, or an
+// empty string when either the namespace or the name is unknown.
+func serviceAccountUsername(namespace, name string) string {
+	if namespace == "" || name == "" {
+		return ""
+	}
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// splitList splits a comma separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+var protectedlabelslog = logf.Log.WithName("protected-labels")
+
+// DefaultProtectedPrefixes are the label prefixes reserved by Kubernetes and
+// by the operator, whose labels such as the parent of a namespace must only be
+// set on the namespaces themselves.
+var DefaultProtectedPrefixes = []string{
+	"kubernetes.io/",
+	"k8s.io/",
+	"pod-security.kubernetes.io/",
+	"namespacelabeler.dana.io/",
+}
+
+// DefaultProtectedAnnotationPrefixes are the annotation prefixes reserved by
+// the operator and kubectl. Kubernetes annotations like the node selector of
+// a namespace are meant to be managed, so they are not protected.
+var DefaultProtectedAnnotationPrefixes = []string{
+	"namespacelabeler.dana.io/",
+	"kubectl.kubernetes.io/",
+}
+
+// ProtectedLabelsSpec lists the label keys NamespaceLabels are not allowed to set.
+type ProtectedLabelsSpec struct {
+	// Prefixes protects every key starting with one of them, e.g. "dana.io/".
+	Prefixes []string `json:"prefixes,omitempty"`
+
+	// Keys protects the exact keys.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// ProtectedLabels holds the protected label prefixes and keys, merged from the
+// flags and a config file. The config file is reloaded whenever it changes, so
+// the protected labels can be changed without restarting the manager.
+type ProtectedLabels struct {
+	base ProtectedLabelsSpec
+	path string
+
+	mu      sync.RWMutex
+	current ProtectedLabelsSpec
+}
+
+// NewProtectedLabels returns the protected labels of the flags, along with the
+// ones of the config file at path when it is set. A missing config file
+// protects nothing more than the flags, so it can be mounted from an optional
+// ConfigMap.
+func NewProtectedLabels(prefixes, keys []string, path string) (*ProtectedLabels, error) {
+	p := &ProtectedLabels{
+		base: ProtectedLabelsSpec{Prefixes: prefixes, Keys: keys},
+		path: path,
+	}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Protects returns whether the key is protected, and the prefix or key protecting it.
+func (p *ProtectedLabels) Protects(key string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, protected := range p.current.Keys {
+		if key == protected {
+			return protected, true
+		}
+	}
+	for _, prefix := range p.current.Prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// Reload reads the config file again. The previous protected labels are kept
+// if the file can't be read.
+func (p *ProtectedLabels) Reload() error {
+	spec := ProtectedLabelsSpec{
+		Prefixes: append([]string{}, p.base.Prefixes...),
+		Keys:     append([]string{}, p.base.Keys...),
+	}
+
+	if p.path != "" {
+		data, err := os.ReadFile(p.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		var fromFile ProtectedLabelsSpec
+		if err := yaml.Unmarshal(data, &fromFile); err != nil {
+			return err
+		}
+		spec.Prefixes = append(spec.Prefixes, fromFile.Prefixes...)
+		spec.Keys = append(spec.Keys, fromFile.Keys...)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = spec
+	return nil
+}
+
+// Start implements manager.Runnable to reload the config file on every change
+// until the context is done. The directory of the file is watched rather than
+// the file itself, since a mounted ConfigMap is updated by swapping a symlink.
+func (p *ProtectedLabels) Start(ctx context.Context) error {
+	if p.path == "" {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(p.path)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			if err := p.Reload(); err != nil {
+				protectedlabelslog.Error(err, "unable to reload the protected labels, keeping the previous ones", "path", p.path)
+				continue
+			}
+			protectedlabelslog.Info("reloaded the protected labels", "path", p.path)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			protectedlabelslog.Error(err, "error watching the protected labels", "path", p.path)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica
+// serves webhooks so every replica needs the protected labels.
+func (p *ProtectedLabels) NeedLeaderElection() bool {
+	return false
+}
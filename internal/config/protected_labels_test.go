@@ -0,0 +1,94 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"dana.io/hello-world/internal/config"
+)
+
+func TestConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Config Suite")
+}
+
+var _ = Describe("ProtectedLabels", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "protected-labels.yaml")
+	})
+
+	It("should protect the prefixes and exact keys of the flags", func() {
+		protectedLabels, err := config.NewProtectedLabels([]string{"kubernetes.io/"}, []string{"team"}, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		protectedBy, protected := protectedLabels.Protects("kubernetes.io/metadata.name")
+		Expect(protected).To(BeTrue())
+		Expect(protectedBy).To(Equal("kubernetes.io/"))
+
+		protectedBy, protected = protectedLabels.Protects("team")
+		Expect(protected).To(BeTrue())
+		Expect(protectedBy).To(Equal("team"))
+
+		_, protected = protectedLabels.Protects("team-name")
+		Expect(protected).To(BeFalse())
+	})
+
+	It("should protect nothing more when the config file is missing", func() {
+		protectedLabels, err := config.NewProtectedLabels(nil, nil, path)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, protected := protectedLabels.Protects("dana.io/team")
+		Expect(protected).To(BeFalse())
+	})
+
+	It("should reload the config file when it changes", func() {
+		Expect(os.WriteFile(path, []byte("prefixes:\n- dana.io/\n"), 0o600)).To(Succeed())
+
+		protectedLabels, err := config.NewProtectedLabels([]string{"kubernetes.io/"}, nil, path)
+		Expect(err).NotTo(HaveOccurred())
+		_, protected := protectedLabels.Protects("dana.io/team")
+		Expect(protected).To(BeTrue())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(protectedLabels.Start(ctx)).To(Succeed())
+		}()
+
+		// Give the watcher time to start before changing the file
+		time.Sleep(100 * time.Millisecond)
+		Expect(os.WriteFile(path, []byte("keys:\n- team\n"), 0o600)).To(Succeed())
+
+		Eventually(func() bool {
+			_, protected := protectedLabels.Protects("team")
+			return protected
+		}, 5*time.Second, 50*time.Millisecond).Should(BeTrue())
+
+		_, protected = protectedLabels.Protects("dana.io/team")
+		Expect(protected).To(BeFalse())
+		_, protected = protectedLabels.Protects("kubernetes.io/metadata.name")
+		Expect(protected).To(BeTrue())
+	})
+
+	It("should keep the previous protected labels when the config file is invalid", func() {
+		Expect(os.WriteFile(path, []byte("keys:\n- team\n"), 0o600)).To(Succeed())
+
+		protectedLabels, err := config.NewProtectedLabels(nil, nil, path)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(path, []byte("keys: {"), 0o600)).To(Succeed())
+		Expect(protectedLabels.Reload()).NotTo(Succeed())
+
+		_, protected := protectedLabels.Protects("team")
+		Expect(protected).To(BeTrue())
+	})
+})
@@ -0,0 +1,422 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+	"dana.io/hello-world/internal/metrics"
+)
+
+const (
+	clusterNamespaceLabelFinalizerName = "namespacelabeller.dana.io/cluster-finalizer"
+
+	reasonNamespacesFailed = "NamespacesFailed"
+	reasonListFailed       = "ListNamespacesFailed"
+)
+
+// ClusterNamespaceLabelReconciler reconciles a ClusterNamespaceLabel object
+type ClusterNamespaceLabelReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// ProtectedLabels are the label keys left out of the labels of the
+	// ClusterNamespaceLabels, which may predate the protection of a key, and
+	// of the ConfigMaps and Secrets of the NamespaceLabels merged with them.
+	ProtectedLabels danaiodanaiov1alpha1.ProtectedLabels
+
+	// AuthorizedKeys are glob patterns of the label keys the webhook
+	// authorizes, the sources of the merged NamespaceLabels are not allowed to set them.
+	AuthorizedKeys []string
+
+	// Clock decides when the time-bound NamespaceLabels merged with the
+	// ClusterNamespaceLabels are active, the real clock is used when unset.
+	Clock clock.PassiveClock
+
+	// PauseAll suspends the reconciliation of every ClusterNamespaceLabel.
+	PauseAll bool
+}
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=dana.io.dana.io,resources=clusternamespacelabels,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=dana.io.dana.io,resources=clusternamespacelabels/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=dana.io.dana.io,resources=clusternamespacelabels/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// HandleCreation handles the creation phase, including adding finalizers.
+func (r *ClusterNamespaceLabelReconciler) HandleCreation(ctx context.Context, clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel) error {
+	if !controllerutil.ContainsFinalizer(clusterNamespaceLabel, clusterNamespaceLabelFinalizerName) {
+		controllerutil.AddFinalizer(clusterNamespaceLabel, clusterNamespaceLabelFinalizerName)
+		return r.Update(ctx, clusterNamespaceLabel)
+	}
+	return nil
+}
+
+// HandleDeletion handles the deletion phase, removing the labels from every
+// namespace before removing the finalizer.
+func (r *ClusterNamespaceLabelReconciler) HandleDeletion(ctx context.Context, clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel) error {
+	if controllerutil.ContainsFinalizer(clusterNamespaceLabel, clusterNamespaceLabelFinalizerName) {
+		// the ClusterNamespaceLabel is being deleted so it is left out of the
+		// merge of every namespace it applied labels to
+		results, err := r.UpdateLabels(ctx, clusterNamespaceLabel)
+		if err != nil {
+			return err
+		}
+		if err := failedNamespaces(results); err != nil {
+			return err
+		}
+		controllerutil.RemoveFinalizer(clusterNamespaceLabel, clusterNamespaceLabelFinalizerName)
+		return r.Update(ctx, clusterNamespaceLabel)
+	}
+	return nil
+}
+
+// UpdateLabels updates the labels of every namespace that is selected by the
+// ClusterNamespaceLabel, or that still holds labels it applied before. It
+// returns the result for every selected namespace and every namespace that
+// failed, the returned error is only set when the namespaces can't be listed.
+func (r *ClusterNamespaceLabelReconciler) UpdateLabels(ctx context.Context, clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel) ([]danaiodanaiov1alpha1.NamespaceApplyStatus, error) {
+	owner := utils.ClusterOwner(clusterNamespaceLabel.Name)
+
+	var namespaceList corev1.NamespaceList
+	if err := r.List(ctx, &namespaceList); err != nil {
+		return nil, err
+	}
+
+	lastApplied := make(map[string]map[string]string)
+	lastConflicts := make(map[string][]danaiodanaiov1alpha1.LabelConflict)
+	lastMessages := make(map[string]string)
+	deleted := make(map[string]struct{})
+	for _, namespaceStatus := range clusterNamespaceLabel.Status.Namespaces {
+		deleted[namespaceStatus.Name] = struct{}{}
+		lastApplied[namespaceStatus.Name] = namespaceStatus.LastAppliedLabels
+		lastConflicts[namespaceStatus.Name] = namespaceStatus.Conflicts
+		lastMessages[namespaceStatus.Name] = namespaceStatus.Message
+	}
+
+	var results []danaiodanaiov1alpha1.NamespaceApplyStatus
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		delete(deleted, namespace.Name)
+
+		selected, err := clusterNamespaceLabel.Spec.NamespaceSelector.Selects(namespace)
+		if err != nil {
+			return nil, err
+		}
+		owners, err := utils.ParseOwners(namespace.ObjectMeta.Annotations, utils.OwnersAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		if !selected && len(owners.KeysOf(owner)) == 0 && len(lastApplied[namespace.Name]) == 0 {
+			continue
+		}
+
+		result := danaiodanaiov1alpha1.NamespaceApplyStatus{Name: namespace.Name}
+		merged, err := r.updateNamespace(ctx, clusterNamespaceLabel, namespace, lastApplied[namespace.Name])
+		if err != nil {
+			r.Recorder.Eventf(clusterNamespaceLabel, corev1.EventTypeWarning, reasonUpdateLabelsFailed,
+				"Failed to update the labels of namespace %s: %v", namespace.Name, err)
+			r.Recorder.Eventf(namespace, corev1.EventTypeWarning, reasonUpdateLabelsFailed,
+				"ClusterNamespaceLabel %s failed to update the labels: %v", clusterNamespaceLabel.Name, err)
+
+			// keep the labels applied before so they are removed on retry
+			result.LastAppliedLabels = lastApplied[namespace.Name]
+			result.Message = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		if selected {
+			result.Applied = true
+			result.LastAppliedLabels = appliedLabels(owner, merged)
+			result.Conflicts = labelConflicts(owner, merged)
+			rendered, renderErr := clusterLabels(clusterNamespaceLabel, namespace, r.ProtectedLabels)
+			result.RenderedLabels = templatedLabels(clusterNamespaceLabel.Spec.Labels, rendered)
+			if renderErr != nil {
+				result.Message = renderErr.Error()
+				if result.Message != lastMessages[namespace.Name] {
+					r.Recorder.Eventf(clusterNamespaceLabel, corev1.EventTypeWarning, reasonTemplateFailed,
+						"Failed to render the labels of namespace %s: %v", namespace.Name, renderErr)
+				}
+			}
+			reportConflicts(r.Recorder, clusterNamespaceLabel, namespace.Name, lastConflicts[namespace.Name], result.Conflicts)
+			results = append(results, result)
+		}
+	}
+
+	// the namespaces deleted since the last reconciliation went away with the
+	// labels they were reported to hold
+	for name := range deleted {
+		metrics.ManagedLabels.DeleteLabelValues(name)
+	}
+
+	return results, nil
+}
+
+// failedNamespaces returns an error naming the namespaces that could not be updated.
+func failedNamespaces(results []danaiodanaiov1alpha1.NamespaceApplyStatus) error {
+	var errs []error
+	for _, result := range results {
+		if !result.Applied {
+			errs = append(errs, fmt.Errorf("namespace %s: %s", result.Name, result.Message))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// updateNamespace applies the merged labels of the namespace.
+func (r *ClusterNamespaceLabelReconciler) updateNamespace(ctx context.Context, clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel,
+	namespace *corev1.Namespace, lastApplied map[string]string) (utils.MergeResult, error) {
+	merged, err := mergeNamespaceLabels(ctx, r.Client, namespace, clusterNamespaceLabel, r.mergeOptions())
+	if err != nil {
+		return utils.MergeResult{}, err
+	}
+
+	diff, err := applyMergedLabels(ctx, r.Client, namespace, utils.ClusterOwner(clusterNamespaceLabel.Name), lastApplied, merged)
+	if err != nil {
+		return utils.MergeResult{}, err
+	}
+	reportLabelChanges(r.Recorder, clusterNamespaceLabel, namespace, lastApplied, diff)
+
+	return merged, nil
+}
+
+// UpdateStatus updates the status of the specified ClusterNamespaceLabel object
+// with the result of every selected namespace.
+func (r *ClusterNamespaceLabelReconciler) UpdateStatus(ctx context.Context, clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel,
+	results []danaiodanaiov1alpha1.NamespaceApplyStatus) error {
+	clusterNamespaceLabel.Status.Namespaces = results
+	updateErr := failedNamespaces(results)
+
+	applied, conflicted, unrendered := 0, 0, 0
+	for _, result := range results {
+		if result.Applied {
+			applied++
+			// applied namespaces only have a message when labels failed to render
+			if result.Message != "" {
+				unrendered++
+			}
+		}
+		if len(result.Conflicts) > 0 {
+			conflicted++
+		}
+	}
+
+	conditions := &clusterNamespaceLabel.Status.Conditions
+	generation := clusterNamespaceLabel.Generation
+	setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionApplied, metav1.ConditionTrue, reasonLabelsApplied,
+		fmt.Sprintf("labels applied to %d of %d namespaces", applied, len(results)))
+
+	if conflicted > 0 {
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionConflicted, metav1.ConditionTrue, reasonLabelsConflicted,
+			fmt.Sprintf("labels were lost to other NamespaceLabels in %d namespaces", conflicted))
+	} else {
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionConflicted, metav1.ConditionFalse, reasonNoConflicts, "")
+	}
+
+	switch {
+	case updateErr != nil:
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionDegraded, metav1.ConditionTrue, reasonNamespacesFailed, updateErr.Error())
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionFalse, reasonNamespacesFailed, updateErr.Error())
+	case conflicted > 0:
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionDegraded, metav1.ConditionFalse, reasonReconciled, "")
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionFalse, reasonLabelsConflicted, "")
+	case unrendered > 0:
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionDegraded, metav1.ConditionFalse, reasonReconciled, "")
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionFalse, reasonTemplateFailed,
+			fmt.Sprintf("labels failed to render for %d namespaces", unrendered))
+	default:
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionDegraded, metav1.ConditionFalse, reasonReconciled, "")
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionTrue, reasonLabelsApplied, "")
+	}
+
+	if meta.FindStatusCondition(*conditions, danaiodanaiov1alpha1.ConditionSuspended) != nil {
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionSuspended, metav1.ConditionFalse, reasonResumed, "")
+	}
+
+	clusterNamespaceLabel.Status.ObservedGeneration = generation
+	return r.Status().Update(ctx, clusterNamespaceLabel)
+}
+
+// updateFailedStatus records a failed reconciliation on the ClusterNamespaceLabel
+// status, the original error is still returned by Reconcile to requeue it.
+func (r *ClusterNamespaceLabelReconciler) updateFailedStatus(ctx context.Context, clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel, reason string, err error) {
+	logger := log.FromContext(ctx)
+
+	r.Recorder.Event(clusterNamespaceLabel, corev1.EventTypeWarning, reason, err.Error())
+
+	conditions := &clusterNamespaceLabel.Status.Conditions
+	setCondition(conditions, clusterNamespaceLabel.Generation, danaiodanaiov1alpha1.ConditionDegraded, metav1.ConditionTrue, reason, err.Error())
+	setCondition(conditions, clusterNamespaceLabel.Generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionFalse, reason, err.Error())
+	clusterNamespaceLabel.Status.ObservedGeneration = clusterNamespaceLabel.Generation
+
+	if statusErr := r.Status().Update(ctx, clusterNamespaceLabel); statusErr != nil {
+		logger.Error(statusErr, "Failed to update status") // Logging the error
+	}
+}
+
+// updatePausedStatus records on the ClusterNamespaceLabel status that the
+// reconciliation of every ClusterNamespaceLabel is paused.
+func (r *ClusterNamespaceLabelReconciler) updatePausedStatus(ctx context.Context, clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel) error {
+	if meta.IsStatusConditionTrue(clusterNamespaceLabel.Status.Conditions, danaiodanaiov1alpha1.ConditionSuspended) {
+		return nil
+	}
+
+	message := "the reconciliation of every ClusterNamespaceLabel is paused by the operator"
+	r.Recorder.Event(clusterNamespaceLabel, corev1.EventTypeNormal, reasonPausedAll, message)
+	setCondition(&clusterNamespaceLabel.Status.Conditions, clusterNamespaceLabel.Generation, danaiodanaiov1alpha1.ConditionSuspended,
+		metav1.ConditionTrue, reasonPausedAll, message)
+	return r.Status().Update(ctx, clusterNamespaceLabel)
+}
+
+// Reconcile applies the labels of a ClusterNamespaceLabel to every namespace it
+// selects, and removes them from the namespaces it no longer selects.
+func (r *ClusterNamespaceLabelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	logger := log.FromContext(ctx)
+
+	clusterNamespaceLabel := danaiodanaiov1alpha1.ClusterNamespaceLabel{}
+	if err := r.Get(ctx, req.NamespacedName, &clusterNamespaceLabel); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("ClusterNamespaceLabel not found", "name", req.Name) // Logging info
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, "Failed to get ClusterNamespaceLabel", "name", req.Name) // Logging the error
+		return ctrl.Result{}, err
+	}
+
+	// examine DeletionTimestamp to determine if object is under deletion, even
+	// while paused so the deletion is never blocked
+	if !clusterNamespaceLabel.ObjectMeta.DeletionTimestamp.IsZero() {
+		if err := r.HandleDeletion(ctx, &clusterNamespaceLabel); err != nil {
+			logger.Error(err, "Failed to handle deletion") // Logging the error
+			r.updateFailedStatus(ctx, &clusterNamespaceLabel, reasonDeletionFailed, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if r.PauseAll {
+		if err := r.updatePausedStatus(ctx, &clusterNamespaceLabel); err != nil {
+			logger.Error(err, "Failed to update status") // Logging the error
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.HandleCreation(ctx, &clusterNamespaceLabel); err != nil {
+		logger.Error(err, "Failed to handle creation") // Logging the error
+		r.updateFailedStatus(ctx, &clusterNamespaceLabel, reasonFinalizerFailed, err)
+		return ctrl.Result{}, err
+	}
+
+	results, err := r.UpdateLabels(ctx, &clusterNamespaceLabel)
+	if err != nil {
+		logger.Error(err, "Failed to list namespaces") // Logging the error
+		r.updateFailedStatus(ctx, &clusterNamespaceLabel, reasonListFailed, err)
+		return ctrl.Result{}, err
+	}
+
+	if err := r.UpdateStatus(ctx, &clusterNamespaceLabel, results); err != nil {
+		logger.Error(err, "Failed to update status") // Logging the error
+		return ctrl.Result{}, err
+	}
+
+	if err := failedNamespaces(results); err != nil {
+		// requeue to retry the namespaces that failed
+		logger.Error(err, "Failed to update labels") // Logging the error
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ClusterNamespaceLabelReconciler) enqueueRequestsFromNamespace(ctx context.Context, o client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+	namespace := o.(*corev1.Namespace)
+	var requests []reconcile.Request
+	var clusterNamespaceLabelList danaiodanaiov1alpha1.ClusterNamespaceLabelList
+
+	if err := r.List(ctx, &clusterNamespaceLabelList); err != nil {
+		logger.Error(err, "Failed to list ClusterNamespaceLabels for namespace", "Namespace", namespace.Name)
+		return []reconcile.Request{}
+	}
+
+	owners, err := utils.ParseOwners(namespace.ObjectMeta.Annotations, utils.OwnersAnnotation)
+	if err != nil {
+		logger.Error(err, "Failed to parse the owners of namespace", "Namespace", namespace.Name)
+	}
+
+	// Enqueue the ClusterNamespaceLabels selecting the namespace, and the ones
+	// that still own labels on it
+	for _, clusterNamespaceLabel := range clusterNamespaceLabelList.Items {
+		selected, err := clusterNamespaceLabel.Spec.NamespaceSelector.Selects(namespace)
+		if err != nil {
+			logger.Error(err, "Failed to match namespace", "ClusterNamespaceLabel", clusterNamespaceLabel.Name)
+		}
+		if !selected && len(owners.KeysOf(utils.ClusterOwner(clusterNamespaceLabel.Name))) == 0 {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: clusterNamespaceLabel.Name},
+		})
+	}
+
+	// Log the number of requests enqueued for the given Namespace
+	logger.Info("Enqueued cluster requests for namespace", "Namespace", namespace.Name, "Number of requests", len(requests))
+
+	return requests
+}
+
+// mergeOptions returns the options of the merges made by the reconciler.
+func (r *ClusterNamespaceLabelReconciler) mergeOptions() mergeOptions {
+	return mergeOptions{protected: r.ProtectedLabels, authorizedKeys: r.AuthorizedKeys, now: r.Clock.Now()}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterNamespaceLabelReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&danaiodanaiov1alpha1.ClusterNamespaceLabel{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.enqueueRequestsFromNamespace)).
+		Complete(r)
+}
@@ -0,0 +1,105 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+)
+
+var _ = Describe("ClusterNamespaceLabelController", Ordered, func() {
+	ctx := context.Background()
+
+	selectorLabels := map[string]string{"clusternamespacelabel-test": "selected"}
+	clusterLabels := map[string]string{"cluster-owner": "platform"}
+
+	var clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel
+	var firstNamespace, laterNamespace *corev1.Namespace
+
+	// namespaceHasLabels returns whether the namespace has all the cluster labels
+	namespaceHasLabels := func(name string) func() bool {
+		return func() bool {
+			ns := &corev1.Namespace{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+				return false
+			}
+			for key, value := range clusterLabels {
+				if nsValue, exists := ns.Labels[key]; !exists || nsValue != value {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	BeforeAll(func() {
+		firstNamespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "clusternamespacelabel-test1", Labels: selectorLabels},
+		}
+		Expect(k8sClient.Create(ctx, firstNamespace)).Should(Succeed())
+
+		clusterNamespaceLabel = &danaiodanaiov1alpha1.ClusterNamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-clusternamespacelabel"},
+			Spec: danaiodanaiov1alpha1.ClusterNamespaceLabelSpec{
+				NamespaceSelector: danaiodanaiov1alpha1.NamespaceSelector{
+					Selector: &metav1.LabelSelector{MatchLabels: selectorLabels},
+				},
+				Labels: clusterLabels,
+			},
+		}
+		Expect(k8sClient.Create(ctx, clusterNamespaceLabel)).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		for _, ns := range []*corev1.Namespace{firstNamespace, laterNamespace} {
+			if ns != nil {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, ns))).Should(Succeed())
+			}
+		}
+	})
+
+	It("Should apply the labels to the selected namespace", func() {
+		Eventually(namespaceHasLabels(firstNamespace.Name), timeout, interval).Should(BeTrue(),
+			"Namespace should get the labels of the ClusterNamespaceLabel")
+	})
+
+	It("Should apply the labels to namespaces created later", func() {
+		laterNamespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "clusternamespacelabel-test2", Labels: selectorLabels},
+		}
+		Expect(k8sClient.Create(ctx, laterNamespace)).Should(Succeed())
+
+		Eventually(namespaceHasLabels(laterNamespace.Name), timeout, interval).Should(BeTrue(),
+			"Namespace created later should get the labels of the ClusterNamespaceLabel")
+
+		By("Waiting for the status to report both namespaces")
+		Eventually(func() int {
+			current := &danaiodanaiov1alpha1.ClusterNamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterNamespaceLabel), current); err != nil {
+				return 0
+			}
+			applied := 0
+			for _, result := range current.Status.Namespaces {
+				if result.Applied {
+					applied++
+				}
+			}
+			return applied
+		}, timeout, interval).Should(Equal(2))
+	})
+
+	It("Should remove the labels when deleted", func() {
+		Expect(k8sClient.Delete(ctx, clusterNamespaceLabel)).Should(Succeed())
+
+		for _, name := range []string{firstNamespace.Name, laterNamespace.Name} {
+			Eventually(namespaceHasLabels(name), timeout, interval).Should(BeFalse(),
+				"Namespace labels should be removed with the ClusterNamespaceLabel")
+		}
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+const (
+	// AnnotationOwnersAnnotation maps every managed annotation of a namespace to
+	// the NamespaceLabels that claim it, like utils.OwnersAnnotation does for
+	// labels.
+	AnnotationOwnersAnnotation = "namespacelabeler.dana.io/annotation-owners"
+
+	// AnnotationFieldManager is the server-side apply field manager of the
+	// managed annotations. It is separate from FieldManager so the annotations
+	// are applied without sending the labels again.
+	AnnotationFieldManager = "namespacelabel-operator-annotations"
+)
+
+// mergeNamespaceAnnotations merges the annotations of every NamespaceLabel in
+// the namespace, the same way mergeNamespaceLabels merges their labels.
+func mergeNamespaceAnnotations(ctx context.Context, c client.Client, namespace *corev1.Namespace, current client.Object, opts mergeOptions) (utils.MergeResult, error) {
+	var claims []utils.LabelClaim
+
+	var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+	if err := c.List(ctx, &namespaceLabelList, client.InNamespace(namespace.Name)); err != nil {
+		return utils.MergeResult{}, err
+	}
+	for i := range namespaceLabelList.Items {
+		namespaceLabel := &namespaceLabelList.Items[i]
+		if current, ok := current.(*danaiodanaiov1alpha1.NamespaceLabel); ok && current.Name == namespaceLabel.Name && current.Namespace == namespaceLabel.Namespace {
+			namespaceLabel = current
+		}
+		if !namespaceLabel.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if !activeWindow(namespaceLabel, opts.now).active {
+			continue
+		}
+		claim := newLabelClaim(namespaceLabel.Name, namespaceLabel.Spec.Annotations,
+			namespaceLabel.Spec.ConflictPolicy, namespaceLabel.Spec.Priority, namespaceLabel.CreationTimestamp)
+		claim.Unenforced = namespaceLabel.Spec.Suspend || !enforces(namespaceLabel)
+		claims = append(claims, claim)
+	}
+
+	return utils.MergeLabels(claims), nil
+}
+
+// applyMergedAnnotations sets the merged annotations on the namespace, and
+// removes the managed annotations no owner claims anymore. It follows
+// applyMergedLabels, with its own owners annotation and field manager.
+func applyMergedAnnotations(ctx context.Context, c client.Client, namespace *corev1.Namespace, lastApplied map[string]string, merged utils.MergeResult) error {
+	owners, err := utils.ParseOwners(namespace.ObjectMeta.Annotations, AnnotationOwnersAnnotation)
+	if err != nil {
+		return err
+	}
+
+	annotationsToRemove := make(map[string]struct{})
+	for key := range owners {
+		if _, exists := merged.Labels[key]; !exists {
+			annotationsToRemove[key] = struct{}{}
+		}
+	}
+	for key := range lastApplied {
+		if _, exists := merged.Labels[key]; !exists {
+			annotationsToRemove[key] = struct{}{}
+		}
+	}
+	for key := range merged.Unenforced {
+		delete(annotationsToRemove, key)
+	}
+
+	annotations := make(map[string]string, len(merged.Labels)+1)
+	for key, value := range merged.Labels {
+		annotations[key] = value
+	}
+	applyOwned, err := fieldManagerKeys(namespace, AnnotationFieldManager, "annotations")
+	if err != nil {
+		return err
+	}
+	for key := range merged.Unenforced {
+		value, exists := namespace.ObjectMeta.Annotations[key]
+		if _, owned := applyOwned[key]; exists && owned {
+			annotations[key] = value
+		}
+	}
+	if err := utils.SetOwners(annotations, AnnotationOwnersAnnotation, merged.Owners); err != nil {
+		return err
+	}
+
+	// namespaces whose annotations were never managed are not written at all
+	if len(annotations) == 0 && len(applyOwned) == 0 && len(annotationsToRemove) == 0 {
+		return nil
+	}
+
+	applied := &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Namespace",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        namespace.Name,
+			Annotations: annotations,
+		},
+	}
+	if err := patchNamespace(ctx, c, applied, "apply", client.Apply, client.FieldOwner(AnnotationFieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	// annotations set by other field managers are not removed by the apply
+	stale := make(map[string]interface{})
+	for key := range annotationsToRemove {
+		if _, exists := applied.ObjectMeta.Annotations[key]; exists {
+			stale[key] = nil
+		}
+	}
+	if len(stale) > 0 {
+		patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": stale}})
+		if err != nil {
+			return err
+		}
+		if err := patchNamespace(ctx, c, applied, "merge", client.RawPatch(types.MergePatchType, patch), client.FieldOwner(AnnotationFieldManager)); err != nil {
+			return err
+		}
+	}
+
+	applied.DeepCopyInto(namespace)
+	return nil
+}
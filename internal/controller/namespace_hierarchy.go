@@ -0,0 +1,173 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+// ParentLabel names the parent of a namespace, either as a label or as an
+// annotation. The labels of the NamespaceLabels with inheritance propagate to
+// every descendant of their namespace.
+const ParentLabel = "namespacelabeler.dana.io/parent"
+
+// inheritedOwner returns the owner name of the labels a NamespaceLabel passes
+// to the descendants of its namespace.
+func inheritedOwner(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// ancestorNamespaces returns the ancestors of the namespace, nearest first. The
+// walk stops at a missing parent, and fails when the parents form a cycle since
+// the namespace has no well-defined ancestors then. The ancestors found before
+// the cycle are returned with the error.
+func ancestorNamespaces(ctx context.Context, c client.Reader, namespace *corev1.Namespace) ([]*corev1.Namespace, error) {
+	var ancestors []*corev1.Namespace
+	path := []string{namespace.Name}
+	visited := map[string]struct{}{namespace.Name: {}}
+
+	for parent := utils.ParentNamespace(namespace, ParentLabel); parent != ""; {
+		path = append(path, parent)
+		if _, exists := visited[parent]; exists {
+			return ancestors, fmt.Errorf("the parents of namespace %s form a cycle: %s", namespace.Name, strings.Join(path, " -> "))
+		}
+		visited[parent] = struct{}{}
+
+		ancestor := &corev1.Namespace{}
+		if err := c.Get(ctx, types.NamespacedName{Name: parent}, ancestor); err != nil {
+			if apierrors.IsNotFound(err) {
+				return ancestors, nil
+			}
+			return ancestors, err
+		}
+		ancestors = append(ancestors, ancestor)
+		parent = utils.ParentNamespace(ancestor, ParentLabel)
+	}
+	return ancestors, nil
+}
+
+// inheritedClaims returns the claims the namespace inherits from the
+// NamespaceLabels of its ancestors. They rank below the claims of the
+// namespace, nearer ancestors first, unless the ancestor denies overrides: its
+// claims then rank above, farther ancestors first.
+func inheritedClaims(ctx context.Context, c client.Client, namespace *corev1.Namespace, current client.Object, opts mergeOptions) ([]utils.LabelClaim, error) {
+	ancestors, err := ancestorNamespaces(ctx, c, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims []utils.LabelClaim
+	for depth, ancestor := range ancestors {
+		var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+		if err := c.List(ctx, &namespaceLabelList, client.InNamespace(ancestor.Name)); err != nil {
+			return nil, err
+		}
+		for i := range namespaceLabelList.Items {
+			namespaceLabel := &namespaceLabelList.Items[i]
+			if current, ok := current.(*danaiodanaiov1alpha1.NamespaceLabel); ok && current.Name == namespaceLabel.Name && current.Namespace == namespaceLabel.Namespace {
+				namespaceLabel = current
+			}
+			if namespaceLabel.Spec.Inheritance == nil || !namespaceLabel.ObjectMeta.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if !activeWindow(namespaceLabel, opts.now).active {
+				continue
+			}
+			labels, err := sourcedLabels(ctx, c, namespaceLabel, opts)
+			if err != nil {
+				return nil, err
+			}
+			// templated values are rendered for the namespace inheriting them
+			labels, _ = renderLabels(inheritedLabels(namespaceLabel.Spec.Inheritance, labels), namespace)
+			if len(labels) == 0 {
+				continue
+			}
+
+			claim := newLabelClaim(inheritedOwner(namespaceLabel.Namespace, namespaceLabel.Name), labels,
+				namespaceLabel.Spec.ConflictPolicy, namespaceLabel.Spec.Priority, namespaceLabel.CreationTimestamp)
+			claim.Level = -(depth + 1)
+			if namespaceLabel.Spec.Inheritance.Overrides == danaiodanaiov1alpha1.DenyOverridePolicy {
+				claim.Level = depth + 1
+			}
+			claim.Unenforced = namespaceLabel.Spec.Suspend || !enforces(namespaceLabel)
+			claims = append(claims, claim)
+		}
+	}
+	return claims, nil
+}
+
+// inheritedLabels returns the labels the descendant namespaces inherit.
+func inheritedLabels(inheritance *danaiodanaiov1alpha1.LabelInheritance, labels map[string]string) map[string]string {
+	inherited := make(map[string]string)
+	for key, value := range labels {
+		inherit, exists := inheritance.Keys[key]
+		if !exists {
+			inherit = inheritance.Inherit
+		}
+		if inherit {
+			inherited[key] = value
+		}
+	}
+	return inherited
+}
+
+// inheritingNamespaces returns the namespaces the labels of the NamespaceLabel
+// may propagate to: the descendants of its namespace while it sets an
+// inheritance, and the namespaces still holding labels it passed on before,
+// such as the ones that moved out of the tree. A NamespaceLabel that never
+// set an inheritance has no inheriting namespaces.
+func inheritingNamespaces(ctx context.Context, c client.Client, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel) ([]*corev1.Namespace, error) {
+	var namespaceList corev1.NamespaceList
+	if err := c.List(ctx, &namespaceList); err != nil {
+		return nil, err
+	}
+
+	var namespaces []*corev1.Namespace
+	if namespaceLabel.Spec.Inheritance != nil && namespaceLabel.ObjectMeta.DeletionTimestamp.IsZero() {
+		namespaces = utils.DescendantNamespaces(namespaceList.Items, namespaceLabel.Namespace, ParentLabel)
+	}
+	found := make(map[string]struct{}, len(namespaces))
+	for _, namespace := range namespaces {
+		found[namespace.Name] = struct{}{}
+	}
+
+	owner := inheritedOwner(namespaceLabel.Namespace, namespaceLabel.Name)
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		if _, exists := found[namespace.Name]; exists || namespace.Name == namespaceLabel.Namespace {
+			continue
+		}
+		owners, err := utils.ParseOwners(namespace.ObjectMeta.Annotations, utils.OwnersAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		if len(owners.KeysOf(owner)) > 0 {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,717 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+	"dana.io/hello-world/internal/metrics"
+	"dana.io/hello-world/pkg/template"
+)
+
+// FieldManager is the server-side apply field manager of every namespace write.
+const FieldManager = "namespacelabel-operator"
+
+// mergeOptions configures the merge of the labels of a namespace.
+type mergeOptions struct {
+	// protected are the label keys the sources of the NamespaceLabels are not
+	// allowed to set.
+	protected danaiodanaiov1alpha1.ProtectedLabels
+
+	// authorizedKeys are glob patterns of the label keys that need an
+	// authorization, only the inline labels the webhook authorized may set them.
+	authorizedKeys []string
+
+	// now decides which of the time-bound NamespaceLabels are active.
+	now time.Time
+}
+
+// mergeNamespaceLabels merges the labels of every NamespaceLabel in the
+// namespace, the labels it inherits from its ancestors and every
+// ClusterNamespaceLabel selecting it. Objects under deletion or inactive are
+// left out, and the current object is preferred over its possibly stale cached
+// copy. The merge fails when the sources of a NamespaceLabel can't be read or
+// the parents of the namespace form a cycle, rather than removing labels.
+func mergeNamespaceLabels(ctx context.Context, c client.Client, namespace *corev1.Namespace, current client.Object, opts mergeOptions) (utils.MergeResult, error) {
+	var claims []utils.LabelClaim
+
+	var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+	if err := c.List(ctx, &namespaceLabelList, client.InNamespace(namespace.Name)); err != nil {
+		return utils.MergeResult{}, err
+	}
+	for i := range namespaceLabelList.Items {
+		namespaceLabel := &namespaceLabelList.Items[i]
+		if current, ok := current.(*danaiodanaiov1alpha1.NamespaceLabel); ok && current.Name == namespaceLabel.Name && current.Namespace == namespaceLabel.Namespace {
+			namespaceLabel = current
+		}
+		if !namespaceLabel.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if !activeWindow(namespaceLabel, opts.now).active {
+			continue
+		}
+		labels, err := sourcedLabels(ctx, c, namespaceLabel, opts)
+		if err != nil {
+			return utils.MergeResult{}, err
+		}
+		// the labels failing to render are reported by their own reconciliation
+		labels, _ = renderLabels(labels, namespace)
+		claim := newLabelClaim(namespaceLabel.Name, labels,
+			namespaceLabel.Spec.ConflictPolicy, namespaceLabel.Spec.Priority, namespaceLabel.CreationTimestamp)
+		// the keys of a suspended NamespaceLabel are left as they are, even by
+		// the other owners of the namespace
+		claim.Unenforced = namespaceLabel.Spec.Suspend || !enforces(namespaceLabel)
+		claims = append(claims, claim)
+	}
+
+	inherited, err := inheritedClaims(ctx, c, namespace, current, opts)
+	if err != nil {
+		return utils.MergeResult{}, err
+	}
+	claims = append(claims, inherited...)
+
+	var clusterNamespaceLabelList danaiodanaiov1alpha1.ClusterNamespaceLabelList
+	if err := c.List(ctx, &clusterNamespaceLabelList); err != nil {
+		return utils.MergeResult{}, err
+	}
+	for i := range clusterNamespaceLabelList.Items {
+		clusterNamespaceLabel := &clusterNamespaceLabelList.Items[i]
+		if current, ok := current.(*danaiodanaiov1alpha1.ClusterNamespaceLabel); ok && current.Name == clusterNamespaceLabel.Name {
+			clusterNamespaceLabel = current
+		}
+		if !clusterNamespaceLabel.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		// a broken ClusterNamespaceLabel is reported by its own reconciliation,
+		// it must not keep the other owners from updating the namespace
+		selected, err := clusterNamespaceLabel.Spec.NamespaceSelector.Selects(namespace)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Skipping the ClusterNamespaceLabel with an invalid namespace selector",
+				"ClusterNamespaceLabel", clusterNamespaceLabel.Name, "Namespace", namespace.Name)
+			continue
+		}
+		if !selected {
+			continue
+		}
+		labels, err := clusterLabels(clusterNamespaceLabel, namespace, opts.protected)
+		if err != nil {
+			log.FromContext(ctx).Info("Leaving out the invalid labels of the ClusterNamespaceLabel",
+				"ClusterNamespaceLabel", clusterNamespaceLabel.Name, "Namespace", namespace.Name, "error", err.Error())
+		}
+		claims = append(claims, newLabelClaim(utils.ClusterOwner(clusterNamespaceLabel.Name), labels,
+			clusterNamespaceLabel.Spec.ConflictPolicy, clusterNamespaceLabel.Spec.Priority, clusterNamespaceLabel.CreationTimestamp))
+	}
+
+	return utils.MergeLabels(claims), nil
+}
+
+// sourcedLabels returns the labels of the NamespaceLabel, the data entries of
+// its sources overridden by its inline labels. The entries are checked like the
+// webhook checks the inline labels, since they never went through it, and
+// they can't set the keys needing an authorization as nobody authorized them.
+func sourcedLabels(ctx context.Context, c client.Reader, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel,
+	opts mergeOptions) (map[string]string, error) {
+	if len(namespaceLabel.Spec.LabelsFrom) == 0 {
+		return namespaceLabel.Spec.Labels, nil
+	}
+
+	labels := make(map[string]string)
+	for _, source := range namespaceLabel.Spec.LabelsFrom {
+		data, err := sourceData(ctx, c, namespaceLabel.Namespace, source)
+		if err != nil {
+			return nil, fmt.Errorf("NamespaceLabel %s: %w", namespaceLabel.Name, err)
+		}
+
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			if strings.HasPrefix(key, source.Prefix) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := validateSourcedLabel(key, data[key], opts.protected); err != nil {
+				return nil, fmt.Errorf("NamespaceLabel %s: %s: label %s: %w", namespaceLabel.Name, describeSource(source), key, err)
+			}
+			if _, inline := namespaceLabel.Spec.Labels[key]; !inline && matchesAnyKey(opts.authorizedKeys, key) {
+				return nil, fmt.Errorf("NamespaceLabel %s: %s: label %s: the key needs an authorization, it can only be set in spec.labels",
+					namespaceLabel.Name, describeSource(source), key)
+			}
+			labels[key] = data[key]
+		}
+	}
+
+	for key, value := range namespaceLabel.Spec.Labels {
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// sourceData returns the data entries of the ConfigMap or Secret referenced by
+// the source, a missing optional source has no entries.
+func sourceData(ctx context.Context, c client.Reader, namespace string, source danaiodanaiov1alpha1.LabelsSource) (map[string]string, error) {
+	switch {
+	case source.ConfigMapRef != nil:
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.ConfigMapRef.Name}, configMap); err != nil {
+			if apierrors.IsNotFound(err) && source.Optional {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unable to read %s: %w", describeSource(source), err)
+		}
+		return configMap.Data, nil
+	case source.SecretRef != nil:
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.SecretRef.Name}, secret); err != nil {
+			if apierrors.IsNotFound(err) && source.Optional {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unable to read %s: %w", describeSource(source), err)
+		}
+		data := make(map[string]string, len(secret.Data))
+		for key, value := range secret.Data {
+			data[key] = string(value)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+// describeSource names the object referenced by the source, e.g. ConfigMap "team-labels".
+func describeSource(source danaiodanaiov1alpha1.LabelsSource) string {
+	if source.SecretRef != nil {
+		return fmt.Sprintf("Secret %q", source.SecretRef.Name)
+	}
+	return fmt.Sprintf("ConfigMap %q", source.ConfigMapRef.Name)
+}
+
+// validateSourcedLabel checks a label taken from a source against the
+// Kubernetes label syntax and the protected labels, templated values are
+// checked once rendered.
+func validateSourcedLabel(key, value string, protected danaiodanaiov1alpha1.ProtectedLabels) error {
+	if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+		return fmt.Errorf("invalid key: %s", strings.Join(msgs, "; "))
+	}
+	if protected != nil {
+		if protectedBy, isProtected := protected.Protects(key); isProtected {
+			return fmt.Errorf("the key is protected by %q", protectedBy)
+		}
+	}
+	if template.IsTemplate(value) {
+		return nil
+	}
+	if msgs := validation.IsValidLabelValue(value); len(msgs) > 0 {
+		return fmt.Errorf("invalid value: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// matchesAnyKey returns whether the key matches one of the glob patterns.
+func matchesAnyKey(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterLabels returns the labels of the ClusterNamespaceLabel rendered for
+// the namespace. The labels with an invalid or protected key, which the
+// ClusterNamespaceLabels admitted before the keys were protected may hold, are
+// left out and returned in the error like the labels failing to render.
+func clusterLabels(clusterNamespaceLabel *danaiodanaiov1alpha1.ClusterNamespaceLabel, namespace *corev1.Namespace,
+	protected danaiodanaiov1alpha1.ProtectedLabels) (map[string]string, error) {
+	keys := make([]string, 0, len(clusterNamespaceLabel.Spec.Labels))
+	for key := range clusterNamespaceLabel.Spec.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	labels := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value := clusterNamespaceLabel.Spec.Labels[key]
+		if err := validateSourcedLabel(key, value, protected); err != nil {
+			errs = append(errs, fmt.Errorf("label %s: %w", key, err))
+			continue
+		}
+		labels[key] = value
+	}
+
+	rendered, err := renderLabels(labels, namespace)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return rendered, utilerrors.NewAggregate(errs)
+}
+
+// renderLabels renders the templated label values for the namespace. The
+// labels that fail to render, or render to an invalid label value, are left out
+// and returned in the error.
+func renderLabels(labels map[string]string, namespace *corev1.Namespace) (map[string]string, error) {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	data := template.NamespaceData(namespace)
+	rendered := make(map[string]string, len(labels))
+	var errs []error
+	for _, key := range keys {
+		value, err := template.Render(labels[key], data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("label %s: %w", key, err))
+			continue
+		}
+		if msgs := validation.IsValidLabelValue(value); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("label %s: rendered value %q is invalid: %s", key, value, strings.Join(msgs, "; ")))
+			continue
+		}
+		rendered[key] = value
+	}
+	return rendered, utilerrors.NewAggregate(errs)
+}
+
+// templatedLabels returns the rendered values of the templated labels of the
+// spec, nil when none of its values are templates.
+func templatedLabels(labels, rendered map[string]string) map[string]string {
+	var templated map[string]string
+	for key, value := range labels {
+		renderedValue, exists := rendered[key]
+		if !exists || !template.IsTemplate(value) {
+			continue
+		}
+		if templated == nil {
+			templated = make(map[string]string)
+		}
+		templated[key] = renderedValue
+	}
+	return templated
+}
+
+// newLabelClaim describes how an owner competes for its keys.
+func newLabelClaim(owner string, labels map[string]string, policy danaiodanaiov1alpha1.ConflictPolicy, priority int32, created metav1.Time) utils.LabelClaim {
+	claim := utils.LabelClaim{
+		Owner:   owner,
+		Labels:  labels,
+		Created: created.Time,
+		Reject:  policy == danaiodanaiov1alpha1.RejectConflictPolicy,
+	}
+	if policy == danaiodanaiov1alpha1.PriorityConflictPolicy {
+		claim.Priority = priority
+	}
+	return claim
+}
+
+// activity describes when the labels of a NamespaceLabel apply.
+type activity struct {
+	// active is whether the labels apply, reason explains why with one of the
+	// reasons of the Active condition.
+	active bool
+	reason string
+
+	// next is the time the labels are next applied or removed, zero when they
+	// never change anymore.
+	next time.Time
+
+	// window is the window of the schedules the labels apply in.
+	window *danaiodanaiov1alpha1.TimeWindow
+}
+
+// activeWindow returns whether the labels of the NamespaceLabel apply at the
+// given time, according to its time bounds and its schedules. Invalid
+// schedules, denied by the webhook, never open.
+func activeWindow(namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel, now time.Time) activity {
+	var activeFrom, expiresAt time.Time
+	if namespaceLabel.Spec.ActiveFrom != nil {
+		activeFrom = namespaceLabel.Spec.ActiveFrom.Time
+	}
+	if namespaceLabel.Spec.ExpiresAt != nil {
+		expiresAt = namespaceLabel.Spec.ExpiresAt.Time
+	}
+	if namespaceLabel.Spec.TTL != nil {
+		start := activeFrom
+		if start.IsZero() {
+			start = namespaceLabel.CreationTimestamp.Time
+		}
+		if ttlExpiry := start.Add(namespaceLabel.Spec.TTL.Duration); expiresAt.IsZero() || ttlExpiry.Before(expiresAt) {
+			expiresAt = ttlExpiry
+		}
+	}
+
+	switch {
+	case !activeFrom.IsZero() && now.Before(activeFrom):
+		return activity{reason: reasonScheduled, next: activeFrom}
+	case !expiresAt.IsZero() && !now.Before(expiresAt):
+		return activity{reason: reasonExpired}
+	}
+
+	current := activity{active: true, reason: reasonActive, next: expiresAt}
+	if len(namespaceLabel.Spec.Schedules) == 0 {
+		return current
+	}
+
+	// the labels apply while any of the schedules is open
+	current.active, current.reason = false, reasonOutsideSchedule
+	var windowNext time.Time
+	for _, labelSchedule := range namespaceLabel.Spec.Schedules {
+		schedule, err := utils.ParseSchedule(labelSchedule.Cron, labelSchedule.Duration.Duration, labelSchedule.TimeZone)
+		if err != nil {
+			continue
+		}
+		start, end, next := schedule.Window(now)
+		if !start.IsZero() {
+			if current.window == nil || end.After(current.window.End.Time) {
+				current.window = &danaiodanaiov1alpha1.TimeWindow{Start: metav1.NewTime(start), End: metav1.NewTime(end)}
+			}
+			current.active, current.reason = true, reasonActive
+		}
+		if !next.IsZero() && (windowNext.IsZero() || next.Before(windowNext)) {
+			windowNext = next
+		}
+	}
+	if !windowNext.IsZero() && (current.next.IsZero() || windowNext.Before(current.next)) {
+		current.next = windowNext
+	}
+	return current
+}
+
+// enforces returns whether the labels of the NamespaceLabel are applied to the
+// namespace. With the Ignore enforcement they are applied once for every
+// generation of the spec, and with the Report enforcement they never are.
+func enforces(namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel) bool {
+	switch namespaceLabel.Spec.Enforcement {
+	case danaiodanaiov1alpha1.ReportEnforcement:
+		return false
+	case danaiodanaiov1alpha1.IgnoreEnforcement:
+		applied := meta.FindStatusCondition(namespaceLabel.Status.Conditions, danaiodanaiov1alpha1.ConditionApplied)
+		return applied == nil || applied.Status != metav1.ConditionTrue || applied.ObservedGeneration != namespaceLabel.Generation
+	default:
+		return true
+	}
+}
+
+// fieldManagerKeys returns the keys of the labels or annotations of the
+// namespace owned by the given server-side apply field manager, metadata is
+// either "labels" or "annotations".
+func fieldManagerKeys(namespace *corev1.Namespace, manager, metadata string) (map[string]struct{}, error) {
+	keys := make(map[string]struct{})
+	for _, entry := range namespace.ObjectMeta.ManagedFields {
+		if entry.Manager != manager || entry.Operation != metav1.ManagedFieldsOperationApply || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields struct {
+			Metadata map[string]map[string]interface{} `json:"f:metadata"`
+		}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, err
+		}
+		for field := range fields.Metadata["f:"+metadata] {
+			if key, found := strings.CutPrefix(field, "f:"); found {
+				keys[key] = struct{}{}
+			}
+		}
+	}
+	return keys, nil
+}
+
+// applyMergedLabels sets the merged labels on the namespace, and removes the
+// managed keys that no owner claims anymore. lastApplied holds the labels the
+// reconciled owner applied before ownership was tracked on the namespace.
+// Unenforced keys are neither set nor removed.
+//
+// The namespace is written with server-side apply, only the managed labels and
+// the owners and controller update annotations are sent so the writes of other
+// field managers are left untouched. The namespace is refreshed with the result,
+// and the changes made to its labels are returned.
+func applyMergedLabels(ctx context.Context, c client.Client, namespace *corev1.Namespace, owner string, lastApplied map[string]string, merged utils.MergeResult) (utils.LabelDiff, error) {
+	owners, err := utils.ParseOwners(namespace.ObjectMeta.Annotations, utils.OwnersAnnotation)
+	if err != nil {
+		return utils.LabelDiff{}, err
+	}
+
+	labelsToRemove := make(map[string]struct{})
+
+	// Determine which of the managed labels are not wanted anymore
+	for key := range owners {
+		if _, exists := merged.Labels[key]; !exists {
+			labelsToRemove[key] = struct{}{}
+		}
+	}
+	for key := range lastApplied {
+		if _, exists := merged.Labels[key]; !exists {
+			labelsToRemove[key] = struct{}{}
+		}
+	}
+	for key := range merged.Unenforced {
+		delete(labelsToRemove, key)
+	}
+
+	// Unenforced keys the operator applied before keep their current value,
+	// leaving them out of the apply would remove them
+	labels := make(map[string]string, len(merged.Labels))
+	for key, value := range merged.Labels {
+		labels[key] = value
+	}
+	applyOwned, err := fieldManagerKeys(namespace, FieldManager, "labels")
+	if err != nil {
+		return utils.LabelDiff{}, err
+	}
+	for key := range merged.Unenforced {
+		value, exists := namespace.ObjectMeta.Labels[key]
+		if _, owned := applyOwned[key]; exists && owned {
+			labels[key] = value
+		}
+	}
+
+	// Call the utility function to compute the namespace labels
+	desired := namespace.DeepCopy()
+	utils.UpdateNamespaceLabels(desired, merged.Labels, labelsToRemove)
+
+	annotations := make(map[string]string)
+	if err := utils.SetOwners(annotations, utils.OwnersAnnotation, merged.Owners); err != nil {
+		return utils.LabelDiff{}, err
+	}
+	// the provenance only moves when the labels actually change, otherwise the
+	// owners of a namespace would keep overwriting each other
+	if !equality.Semantic.DeepEqual(namespace.ObjectMeta.Labels, desired.ObjectMeta.Labels) {
+		annotations[ControllerUpdateAnnotation] = owner
+	} else if provenance, exists := namespace.ObjectMeta.Annotations[ControllerUpdateAnnotation]; exists {
+		annotations[ControllerUpdateAnnotation] = provenance
+	}
+
+	// the operator is the source of truth of the labels it manages, so it takes
+	// over their ownership from any other field manager
+	applied := &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Namespace",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        namespace.Name,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+	if err := patchNamespace(ctx, c, applied, "apply", client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return utils.LabelDiff{}, err
+	}
+
+	// fields written before the operator used server-side apply are owned by
+	// other field managers, so leaving them out of the apply does not remove them
+	stale := make(map[string]interface{})
+	for key := range labelsToRemove {
+		if _, exists := applied.ObjectMeta.Labels[key]; exists {
+			stale[key] = nil
+		}
+	}
+	_, ownersExist := applied.ObjectMeta.Annotations[utils.OwnersAnnotation]
+	if len(stale) > 0 || (ownersExist && len(merged.Owners) == 0) {
+		metadata := map[string]interface{}{"labels": stale}
+		if ownersExist && len(merged.Owners) == 0 {
+			metadata["annotations"] = map[string]interface{}{utils.OwnersAnnotation: nil}
+		}
+		patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+		if err != nil {
+			return utils.LabelDiff{}, err
+		}
+		if err := patchNamespace(ctx, c, applied, "merge", client.RawPatch(types.MergePatchType, patch), client.FieldOwner(FieldManager)); err != nil {
+			return utils.LabelDiff{}, err
+		}
+	}
+
+	diff := utils.DiffLabels(namespace.ObjectMeta.Labels, applied.ObjectMeta.Labels)
+	applied.DeepCopyInto(namespace)
+	if len(merged.Owners) > 0 {
+		metrics.ManagedLabels.WithLabelValues(namespace.Name).Set(float64(len(merged.Owners)))
+	} else {
+		metrics.ManagedLabels.DeleteLabelValues(namespace.Name)
+	}
+	return diff, nil
+}
+
+// patchNamespace patches the namespace, recording the latency of the write.
+func patchNamespace(ctx context.Context, c client.Client, namespace *corev1.Namespace, operation string, patch client.Patch, opts ...client.PatchOption) error {
+	start := time.Now()
+	err := c.Patch(ctx, namespace, patch, opts...)
+
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	metrics.NamespaceWriteDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// reportLabelChanges emits events on the owner and on the namespace for the
+// changes made to the labels of the namespace. Changes restoring the labels
+// the owner applied before are reported and counted as reverted drift.
+func reportLabelChanges(recorder record.EventRecorder, owner client.Object, namespace *corev1.Namespace, lastApplied map[string]string, diff utils.LabelDiff) {
+	var reverted, updated utils.LabelDiff
+	for _, change := range diff.Added {
+		if value, exists := lastApplied[change.Key]; exists && value == change.New {
+			reverted.Added = append(reverted.Added, change)
+		} else {
+			updated.Added = append(updated.Added, change)
+		}
+	}
+	for _, change := range diff.Changed {
+		if value, exists := lastApplied[change.Key]; exists && value == change.New {
+			reverted.Changed = append(reverted.Changed, change)
+		} else {
+			updated.Changed = append(updated.Changed, change)
+		}
+	}
+	updated.Removed = diff.Removed
+
+	kind := "NamespaceLabel"
+	if _, ok := owner.(*danaiodanaiov1alpha1.ClusterNamespaceLabel); ok {
+		kind = "ClusterNamespaceLabel"
+	}
+
+	if !reverted.Empty() {
+		metrics.DriftReverts.WithLabelValues(namespace.Name).Add(float64(len(reverted.Added) + len(reverted.Changed)))
+		recorder.Eventf(owner, corev1.EventTypeWarning, reasonDriftReverted,
+			"Reverted labels changed on namespace %s: %s", namespace.Name, reverted)
+		recorder.Eventf(namespace, corev1.EventTypeWarning, reasonDriftReverted,
+			"%s %s reverted labels changed on the namespace: %s", kind, owner.GetName(), reverted)
+	}
+	if !updated.Empty() {
+		recorder.Eventf(owner, corev1.EventTypeNormal, reasonLabelsUpdated,
+			"Updated labels of namespace %s: %s", namespace.Name, updated)
+		recorder.Eventf(namespace, corev1.EventTypeNormal, reasonLabelsUpdated,
+			"%s %s updated the labels: %s", kind, owner.GetName(), updated)
+	}
+}
+
+// reportConflicts emits a warning on the owner and counts the conflicts it was
+// not already reporting.
+func reportConflicts(recorder record.EventRecorder, owner client.Object, namespace string, previous, conflicts []danaiodanaiov1alpha1.LabelConflict) {
+	reported := make(map[danaiodanaiov1alpha1.LabelConflict]struct{})
+	for _, conflict := range previous {
+		reported[conflict] = struct{}{}
+	}
+
+	var lost []string
+	for _, conflict := range conflicts {
+		if _, exists := reported[conflict]; exists {
+			continue
+		}
+		if conflict.Winner == "" {
+			lost = append(lost, fmt.Sprintf("%s=%s is rejected by a conflict", conflict.Key, conflict.Value))
+		} else {
+			lost = append(lost, fmt.Sprintf("%s=%s lost to %s with %s", conflict.Key, conflict.Value, conflict.Winner, conflict.AppliedValue))
+		}
+	}
+	if len(lost) == 0 {
+		return
+	}
+
+	metrics.Conflicts.WithLabelValues(namespace).Add(float64(len(lost)))
+	recorder.Eventf(owner, corev1.EventTypeWarning, reasonLabelsConflicted,
+		"Labels conflicted on namespace %s: %s", namespace, strings.Join(lost, ", "))
+}
+
+// appliedLabels returns the merged labels the given owner won.
+func appliedLabels(owner string, merged utils.MergeResult) map[string]string {
+	applied := make(map[string]string)
+	for key := range merged.Owners.KeysOf(owner) {
+		applied[key] = merged.Labels[key]
+	}
+	return applied
+}
+
+// unenforcedLabels returns the merged labels the given owner won but that are
+// not enforced on the namespace.
+func unenforcedLabels(owner string, merged utils.MergeResult) map[string]string {
+	unenforced := make(map[string]string)
+	for key := range merged.UnenforcedOwners.KeysOf(owner) {
+		unenforced[key] = merged.Unenforced[key]
+	}
+	return unenforced
+}
+
+// labelDrift returns the labels the given owner won but that are not enforced,
+// and that differ on the namespace, sorted by key.
+func labelDrift(owner string, merged utils.MergeResult, namespace *corev1.Namespace) []danaiodanaiov1alpha1.LabelDrift {
+	var drift []danaiodanaiov1alpha1.LabelDrift
+	for key, value := range unenforcedLabels(owner, merged) {
+		observed, exists := namespace.ObjectMeta.Labels[key]
+		if exists && observed == value {
+			continue
+		}
+		drift = append(drift, danaiodanaiov1alpha1.LabelDrift{
+			Key:           key,
+			Value:         value,
+			ObservedValue: observed,
+			Missing:       !exists,
+		})
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		return drift[i].Key < drift[j].Key
+	})
+	return drift
+}
+
+// labelConflicts returns the keys the given owner lost as status conflicts.
+func labelConflicts(owner string, merged utils.MergeResult) []danaiodanaiov1alpha1.LabelConflict {
+	var conflicts []danaiodanaiov1alpha1.LabelConflict
+	for _, conflict := range merged.Conflicts[owner] {
+		conflicts = append(conflicts, danaiodanaiov1alpha1.LabelConflict{
+			Key:          conflict.Key,
+			Value:        conflict.Value,
+			Winner:       conflict.Winner,
+			AppliedValue: conflict.WinnerValue,
+		})
+	}
+	return conflicts
+}
+
+// setCondition sets a status condition for the given generation.
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
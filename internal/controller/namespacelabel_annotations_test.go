@@ -0,0 +1,81 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller"
+)
+
+var _ = Describe("NamespaceLabel annotations", Ordered, func() {
+	ctx := context.Background()
+
+	var namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel
+
+	namespace := testNamespace(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "namespacelabel-annotations-test",
+			Annotations: map[string]string{"unmanaged": "value"},
+		},
+	})
+
+	It("Should apply the annotations and record their owners", func() {
+		namespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "annotations-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels:      map[string]string{"team": "apps"},
+				Annotations: map[string]string{"cost-center": "1234", "openshift.io/node-selector": "tier=apps"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespaceLabel)).Should(Succeed())
+
+		Eventually(namespaceAnnotations(namespace), timeout, interval).Should(SatisfyAll(
+			HaveKeyWithValue("cost-center", "1234"),
+			HaveKeyWithValue("openshift.io/node-selector", "tier=apps"),
+			HaveKeyWithValue("unmanaged", "value"),
+			HaveKey(controller.AnnotationOwnersAnnotation),
+		))
+
+		Eventually(func() map[string]string {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel), current); err != nil {
+				return nil
+			}
+			return current.Status.LastAppliedAnnotations
+		}, timeout, interval).Should(Equal(namespaceLabel.Spec.Annotations))
+	})
+
+	It("Should remove the annotations dropped from the spec", func() {
+		Eventually(func() error {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel), current); err != nil {
+				return err
+			}
+			delete(current.Spec.Annotations, "cost-center")
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(namespaceAnnotations(namespace), timeout, interval).ShouldNot(HaveKey("cost-center"))
+		Expect(namespaceAnnotations(namespace)()).To(SatisfyAll(
+			HaveKeyWithValue("openshift.io/node-selector", "tier=apps"),
+			HaveKeyWithValue("unmanaged", "value"),
+		))
+	})
+
+	It("Should remove every managed annotation on deletion", func() {
+		Expect(k8sClient.Delete(ctx, namespaceLabel)).Should(Succeed())
+
+		Eventually(namespaceAnnotations(namespace), timeout, interval).ShouldNot(SatisfyAny(
+			HaveKey("openshift.io/node-selector"),
+			HaveKey(controller.AnnotationOwnersAnnotation),
+		))
+		Expect(namespaceAnnotations(namespace)()).To(HaveKeyWithValue("unmanaged", "value"))
+	})
+})
@@ -18,11 +18,20 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -31,25 +40,88 @@ import (
 
 	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
 	"dana.io/hello-world/internal/controller/utils"
+	"dana.io/hello-world/internal/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const (
+	// ControllerUpdateAnnotation records the owner whose reconciliation last
+	// changed the labels of a namespace.
 	ControllerUpdateAnnotation = "namespacelabeler.dana.io/controller-update"
 
 	namespaceLabelFinalizerName = "namespacelabeller.dana.io/finalizer"
 )
 
+// Reasons of the conditions set on the NamespaceLabel status.
+const (
+	reasonLabelsApplied           = "LabelsApplied"
+	reasonLabelsConflicted        = "LabelsConflicted"
+	reasonNoConflicts             = "NoConflicts"
+	reasonReconciled              = "Reconciled"
+	reasonNamespaceNotFound       = "NamespaceNotFound"
+	reasonGetNamespaceFailed      = "GetNamespaceFailed"
+	reasonFinalizerFailed         = "FinalizerFailed"
+	reasonUpdateLabelsFailed      = "UpdateLabelsFailed"
+	reasonDeletionFailed          = "DeletionFailed"
+	reasonReportOnly              = "ReportOnly"
+	reasonLabelsDrifted           = "LabelsDrifted"
+	reasonNoDrift                 = "NoDrift"
+	reasonLabelsUpdated           = "LabelsUpdated"
+	reasonDriftReverted           = "DriftReverted"
+	reasonSuspended               = "Suspended"
+	reasonPausedAll               = "PausedAll"
+	reasonResumed                 = "Resumed"
+	reasonTemplateFailed          = "TemplateFailed"
+	reasonUpdateAnnotationsFailed = "UpdateAnnotationsFailed"
+	reasonActive                  = "Active"
+	reasonScheduled               = "Scheduled"
+	reasonExpired                 = "Expired"
+	reasonOutsideSchedule         = "OutsideSchedule"
+	reasonPropagateLabelsFailed   = "PropagateLabelsFailed"
+	reasonCompliant               = "Compliant"
+	reasonPolicyViolated          = "PolicyViolated"
+)
+
+// Field indexes of the NamespaceLabels, by the names of the objects their
+// labelsFrom reference.
+const (
+	labelsFromConfigMapIndex = ".spec.labelsFrom.configMapRef.name"
+	labelsFromSecretIndex    = ".spec.labelsFrom.secretRef.name"
+)
+
 // NamespaceLabelReconciler reconciles a NamespaceLabel object
 type NamespaceLabelReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// ProtectedLabels are the label keys the ConfigMaps and Secrets referenced
+	// by the NamespaceLabels are not allowed to set.
+	ProtectedLabels danaiodanaiov1alpha1.ProtectedLabels
+
+	// AuthorizedKeys are glob patterns of the label keys the webhook
+	// authorizes, the ConfigMaps and Secrets are not allowed to set them.
+	AuthorizedKeys []string
+
+	// Clock decides when time-bound NamespaceLabels are active, the real clock
+	// is used when unset.
+	Clock clock.PassiveClock
+
+	// APIReader lists the objects the labels are propagated to from the API
+	// server, the reader of the manager is used when unset.
+	APIReader client.Reader
+
+	// PauseAll suspends the reconciliation of every NamespaceLabel.
+	PauseAll bool
 }
 
-//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=dana.io.dana.io,resources=namespacelabels,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=dana.io.dana.io,resources=namespacelabels/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=dana.io.dana.io,resources=namespacelabels/finalizers,verbs=update
+//+kubebuilder:rbac:groups=dana.io.dana.io,resources=namespacelabelpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -67,18 +139,40 @@ func (r *NamespaceLabelReconciler) deleteExternalResources(ctx context.Context,
 
 	//logger := log.FromContext(ctx)
 
-	for key, _ := range namespaceLabel.Spec.Labels {
-		delete(namespace.ObjectMeta.Labels, key)
+	// the NamespaceLabel is being deleted so it is left out of the merge, keys
+	// still claimed by another NamespaceLabel in the namespace are left in place
+	merged, err := mergeNamespaceLabels(ctx, r.Client, namespace, namespaceLabel, r.mergeOptions())
+	if err != nil {
+		return err
 	}
-
-	namespace.ObjectMeta.Annotations = make(map[string]string)
-	namespace.ObjectMeta.Annotations[ControllerUpdateAnnotation] = "true"
 	// update the namespace with the new labels
-	if err := r.Update(ctx, namespace); err != nil {
+	diff, err := applyMergedLabels(ctx, r.Client, namespace, namespaceLabel.Name, namespaceLabel.Status.LastAppliedLabels, merged)
+	if err != nil {
 		return err
 	}
+	reportLabelChanges(r.Recorder, namespaceLabel, namespace, nil, diff)
 
-	return nil
+	// a terminating namespace goes away with its labels, the ones other owners
+	// still claim are not reported anymore
+	if !namespace.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.ManagedLabels.DeleteLabelValues(namespace.Name)
+	}
+
+	// the labels the descendants inherited from it are removed as well
+	if err := r.updateInheritingNamespaces(ctx, namespaceLabel); err != nil {
+		return err
+	}
+
+	mergedAnnotations, err := mergeNamespaceAnnotations(ctx, r.Client, namespace, namespaceLabel, r.mergeOptions())
+	if err != nil {
+		return err
+	}
+	if err := applyMergedAnnotations(ctx, r.Client, namespace, namespaceLabel.Status.LastAppliedAnnotations, mergedAnnotations); err != nil {
+		return err
+	}
+
+	// the labels it propagated to the objects of the namespace are removed too
+	return propagateLabels(ctx, r.Client, r.APIReader, namespace)
 
 }
 
@@ -107,35 +201,320 @@ func (r *NamespaceLabelReconciler) HandleDeletion(ctx context.Context, namespace
 	return nil
 }
 
-// UpdateLabels updates the labels of the specified namespace.
-func (r *NamespaceLabelReconciler) UpdateLabels(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel, namespace *corev1.Namespace) error {
-	labelsToAdd := namespaceLabel.Spec.Labels
-	labelsToRemove := make(map[string]struct{})
+// UpdateLabels updates the labels of the specified namespace with the merged
+// labels of all the NamespaceLabels and ClusterNamespaceLabels applying to it,
+// and returns the merge result.
+func (r *NamespaceLabelReconciler) UpdateLabels(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel, namespace *corev1.Namespace) (utils.MergeResult, error) {
+	merged, err := mergeNamespaceLabels(ctx, r.Client, namespace, namespaceLabel, r.mergeOptions())
+	if err != nil {
+		return utils.MergeResult{}, err
+	}
+
+	// Update the namespace with the new labels
+	diff, err := applyMergedLabels(ctx, r.Client, namespace, namespaceLabel.Name, namespaceLabel.Status.LastAppliedLabels, merged)
+	if err != nil {
+		return utils.MergeResult{}, err
+	}
+	reportLabelChanges(r.Recorder, namespaceLabel, namespace, namespaceLabel.Status.LastAppliedLabels, diff)
+
+	if err := r.updateInheritingNamespaces(ctx, namespaceLabel); err != nil {
+		return utils.MergeResult{}, err
+	}
+
+	return merged, nil
+}
+
+// updateInheritingNamespaces updates the labels of the namespaces the labels
+// of the NamespaceLabel propagate to, so they follow it even when none of their
+// own NamespaceLabels is reconciled.
+func (r *NamespaceLabelReconciler) updateInheritingNamespaces(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel) error {
+	namespaces, err := inheritingNamespaces(ctx, r.Client, namespaceLabel)
+	if err != nil {
+		return err
+	}
 
-	// Determine which labels to remove
-	for key := range namespaceLabel.Status.LastAppliedLabels {
-		if _, exists := namespaceLabel.Spec.Labels[key]; !exists {
-			labelsToRemove[key] = struct{}{}
+	owner := inheritedOwner(namespaceLabel.Namespace, namespaceLabel.Name)
+	var errs []error
+	for _, namespace := range namespaces {
+		merged, err := mergeNamespaceLabels(ctx, r.Client, namespace, namespaceLabel, r.mergeOptions())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", namespace.Name, err))
+			continue
 		}
+		diff, err := applyMergedLabels(ctx, r.Client, namespace, owner, nil, merged)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", namespace.Name, err))
+			continue
+		}
+		reportLabelChanges(r.Recorder, namespaceLabel, namespace, nil, diff)
 	}
+	return utilerrors.NewAggregate(errs)
+}
 
-	// Call the utility function to update the namespace labels
-	utils.UpdateNamespaceLabels(namespace, labelsToAdd, labelsToRemove)
+// UpdateAnnotations updates the annotations of the specified namespace with the
+// merged annotations of all the NamespaceLabels in it. The annotations applied
+// by the NamespaceLabel are recorded on its status, written by UpdateStatus.
+func (r *NamespaceLabelReconciler) UpdateAnnotations(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel, namespace *corev1.Namespace) error {
+	merged, err := mergeNamespaceAnnotations(ctx, r.Client, namespace, namespaceLabel, r.mergeOptions())
+	if err != nil {
+		return err
+	}
+	if err := applyMergedAnnotations(ctx, r.Client, namespace, namespaceLabel.Status.LastAppliedAnnotations, merged); err != nil {
+		return err
+	}
 
-	// Update the namespace with the new labels
-	if err := r.Update(ctx, namespace); err != nil {
+	if namespaceLabel.Spec.Enforcement == danaiodanaiov1alpha1.ReportEnforcement {
+		namespaceLabel.Status.LastAppliedAnnotations = nil
+		return nil
+	}
+	applied := appliedLabels(namespaceLabel.Name, merged)
+	for key, value := range unenforcedLabels(namespaceLabel.Name, merged) {
+		applied[key] = value
+	}
+	namespaceLabel.Status.LastAppliedAnnotations = applied
+	return nil
+}
+
+// UpdateStatus updates the status of the specified NamespaceLabel object with
+// the labels it won and the ones it lost to other NamespaceLabels. With the
+// Report enforcement the labels that drifted on the namespace are reported instead.
+func (r *NamespaceLabelReconciler) UpdateStatus(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel, namespace *corev1.Namespace, merged utils.MergeResult) error {
+	conflicts := labelConflicts(namespaceLabel.Name, merged)
+	reportConflicts(r.Recorder, namespaceLabel, namespace.Name, namespaceLabel.Status.Conflicts, conflicts)
+	namespaceLabel.Status.Conflicts = conflicts
+	namespaceLabel.Status.PropagatedKinds = specPropagationKinds(namespaceLabel)
+
+	conditions := &namespaceLabel.Status.Conditions
+	generation := namespaceLabel.Generation
+
+	labels, err := sourcedLabels(ctx, r.Client, namespaceLabel, r.mergeOptions())
+	if err != nil {
 		return err
 	}
+	rendered, renderErr := renderLabels(labels, namespace)
+	namespaceLabel.Status.RenderedLabels = templatedLabels(labels, rendered)
+	if renderErr != nil {
+		// only the new rendering failures are recorded, not every reconciliation
+		ready := meta.FindStatusCondition(*conditions, danaiodanaiov1alpha1.ConditionReady)
+		if ready == nil || ready.Reason != reasonTemplateFailed || ready.Message != renderErr.Error() {
+			r.Recorder.Event(namespaceLabel, corev1.EventTypeWarning, reasonTemplateFailed, renderErr.Error())
+		}
+	}
+
+	if namespaceLabel.Spec.Enforcement == danaiodanaiov1alpha1.ReportEnforcement {
+		drift := labelDrift(namespaceLabel.Name, merged, namespace)
+		r.reportDrift(namespaceLabel, drift)
+		namespaceLabel.Status.Drift = drift
+		namespaceLabel.Status.LastAppliedLabels = nil
+
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionApplied, metav1.ConditionFalse, reasonReportOnly,
+			"labels are not applied with the Report enforcement")
+		if len(drift) > 0 {
+			setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionDrifted, metav1.ConditionTrue, reasonLabelsDrifted,
+				fmt.Sprintf("%d labels differ on namespace %s", len(drift), namespaceLabel.Namespace))
+		} else {
+			setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionDrifted, metav1.ConditionFalse, reasonNoDrift, "")
+		}
+	} else {
+		// with the Ignore enforcement the labels were applied for this generation,
+		// even though they are not enforced anymore
+		namespaceLabel.Status.LastAppliedLabels = appliedLabels(namespaceLabel.Name, merged)
+		for key, value := range unenforcedLabels(namespaceLabel.Name, merged) {
+			namespaceLabel.Status.LastAppliedLabels[key] = value
+		}
+		namespaceLabel.Status.Drift = nil
 
-	return r.Update(ctx, namespace)
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionApplied, metav1.ConditionTrue, reasonLabelsApplied,
+			fmt.Sprintf("%d of %d labels applied to namespace %s", len(namespaceLabel.Status.LastAppliedLabels), len(labels), namespaceLabel.Namespace))
+		meta.RemoveStatusCondition(conditions, danaiodanaiov1alpha1.ConditionDrifted)
+	}
+	setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionDegraded, metav1.ConditionFalse, reasonReconciled, "")
+	r.updateActiveStatus(namespaceLabel)
+	if err := r.updateCompliantStatus(ctx, namespaceLabel, namespace, labels, rendered, merged.Labels); err != nil {
+		return err
+	}
+	if err := updateAttentionStatus(namespaceLabel); err != nil {
+		return err
+	}
+
+	switch {
+	case len(namespaceLabel.Status.Conflicts) > 0:
+		message := fmt.Sprintf("%d labels were lost to other NamespaceLabels", len(namespaceLabel.Status.Conflicts))
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionConflicted, metav1.ConditionTrue, reasonLabelsConflicted, message)
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionFalse, reasonLabelsConflicted, message)
+	case renderErr != nil:
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionConflicted, metav1.ConditionFalse, reasonNoConflicts, "")
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionFalse, reasonTemplateFailed, renderErr.Error())
+	case len(namespaceLabel.Status.Drift) > 0:
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionConflicted, metav1.ConditionFalse, reasonNoConflicts, "")
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionFalse, reasonLabelsDrifted,
+			fmt.Sprintf("%d labels differ on namespace %s", len(namespaceLabel.Status.Drift), namespaceLabel.Namespace))
+	default:
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionConflicted, metav1.ConditionFalse, reasonNoConflicts, "")
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionTrue, reasonLabelsApplied, "")
+	}
+
+	if meta.FindStatusCondition(*conditions, danaiodanaiov1alpha1.ConditionSuspended) != nil {
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionSuspended, metav1.ConditionFalse, reasonResumed, "")
+	}
+
+	namespaceLabel.Status.ObservedGeneration = generation
+	return r.Status().Update(ctx, namespaceLabel)
 }
 
-// UpdateStatus updates the status of the specified NamespaceLabel object.
-func (r *NamespaceLabelReconciler) UpdateStatus(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel) error {
-	namespaceLabel.Status.LastAppliedLabels = namespaceLabel.Spec.Labels
+// updateCompliantStatus records on the status whether the labels satisfy the
+// NamespaceLabelPolicies selecting the namespace. The keys and values are
+// enforced on admission, so a violation means a policy changed since the
+// labels were set. Templated labels are checked with their rendered values.
+// The required keys and the maximum number of labels are checked against the
+// labels the NamespaceLabels of the namespace manage together.
+func (r *NamespaceLabelReconciler) updateCompliantStatus(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel, namespace *corev1.Namespace, labels, rendered, managed map[string]string) error {
+	var policyList danaiodanaiov1alpha1.NamespaceLabelPolicyList
+	if err := r.List(ctx, &policyList); err != nil {
+		return err
+	}
+
+	// the namespace is checked even when no label is managed
+	if managed == nil {
+		managed = map[string]string{}
+	}
+
+	checked := make(map[string]string, len(labels))
+	for key, value := range labels {
+		if renderedValue, exists := rendered[key]; exists {
+			value = renderedValue
+		}
+		checked[key] = value
+	}
+	violations, err := danaiodanaiov1alpha1.CheckPolicies(policyList.Items, namespace, checked, managed, field.NewPath("spec", "labels"))
+	if err != nil {
+		return err
+	}
+
+	conditions := &namespaceLabel.Status.Conditions
+	if len(violations) == 0 {
+		setCondition(conditions, namespaceLabel.Generation, danaiodanaiov1alpha1.ConditionCompliant, metav1.ConditionTrue, reasonCompliant, "")
+		return nil
+	}
+
+	// only the new violations are recorded, not every reconciliation
+	message := violations.ToAggregate().Error()
+	compliant := meta.FindStatusCondition(*conditions, danaiodanaiov1alpha1.ConditionCompliant)
+	if compliant == nil || compliant.Status != metav1.ConditionFalse || compliant.Message != message {
+		r.Recorder.Event(namespaceLabel, corev1.EventTypeWarning, reasonPolicyViolated, message)
+	}
+	setCondition(conditions, namespaceLabel.Generation, danaiodanaiov1alpha1.ConditionCompliant, metav1.ConditionFalse, reasonPolicyViolated, message)
+	return nil
+}
+
+// updateActiveStatus records on the status of a time-bound or scheduled
+// NamespaceLabel whether its labels are applied, and when that changes next.
+func (r *NamespaceLabelReconciler) updateActiveStatus(namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel) {
+	conditions := &namespaceLabel.Status.Conditions
+	generation := namespaceLabel.Generation
+
+	if namespaceLabel.Spec.ActiveFrom == nil && namespaceLabel.Spec.ExpiresAt == nil && namespaceLabel.Spec.TTL == nil &&
+		len(namespaceLabel.Spec.Schedules) == 0 {
+		namespaceLabel.Status.NextTransition = nil
+		namespaceLabel.Status.CurrentWindow = nil
+		meta.RemoveStatusCondition(conditions, danaiodanaiov1alpha1.ConditionActive)
+		return
+	}
+
+	current := activeWindow(namespaceLabel, r.Clock.Now())
+	namespaceLabel.Status.CurrentWindow = current.window
+	namespaceLabel.Status.NextTransition = nil
+	if !current.next.IsZero() {
+		namespaceLabel.Status.NextTransition = &metav1.Time{Time: current.next}
+	}
+
+	var message string
+	switch {
+	case current.active && current.next.IsZero():
+		message = "the labels are applied"
+	case current.active:
+		message = fmt.Sprintf("the labels are applied until %s", current.next.UTC().Format(time.RFC3339))
+	case current.reason == reasonExpired:
+		message = "the labels expired"
+	case current.next.IsZero():
+		message = "the labels are not applied, and no schedule opens again"
+	default:
+		message = fmt.Sprintf("the labels are applied from %s", current.next.UTC().Format(time.RFC3339))
+	}
+
+	if current.active {
+		setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionActive, metav1.ConditionTrue, current.reason, message)
+		return
+	}
+	setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionActive, metav1.ConditionFalse, current.reason, message)
+	setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionApplied, metav1.ConditionFalse, current.reason, message)
+}
+
+// updateSuspendedStatus records on the NamespaceLabel status that its
+// reconciliation is suspended, the rest of the status is left as it was.
+func (r *NamespaceLabelReconciler) updateSuspendedStatus(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel) error {
+	reason, message := reasonSuspended, "the reconciliation is suspended by the spec"
+	if r.PauseAll {
+		reason, message = reasonPausedAll, "the reconciliation of every NamespaceLabel is paused by the operator"
+	}
+
+	condition := meta.FindStatusCondition(namespaceLabel.Status.Conditions, danaiodanaiov1alpha1.ConditionSuspended)
+	if condition != nil && condition.Status == metav1.ConditionTrue && condition.Reason == reason &&
+		condition.ObservedGeneration == namespaceLabel.Generation {
+		return nil
+	}
+
+	r.Recorder.Event(namespaceLabel, corev1.EventTypeNormal, reason, message)
+	setCondition(&namespaceLabel.Status.Conditions, namespaceLabel.Generation, danaiodanaiov1alpha1.ConditionSuspended, metav1.ConditionTrue, reason, message)
 	return r.Status().Update(ctx, namespaceLabel)
 }
 
+// reportDrift emits an Event and counts the labels that drifted since the
+// drift last reported on the status.
+func (r *NamespaceLabelReconciler) reportDrift(namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel, drift []danaiodanaiov1alpha1.LabelDrift) {
+	reported := make(map[danaiodanaiov1alpha1.LabelDrift]struct{})
+	for _, labelDrift := range namespaceLabel.Status.Drift {
+		reported[labelDrift] = struct{}{}
+	}
+
+	var drifted []string
+	for _, labelDrift := range drift {
+		if _, exists := reported[labelDrift]; exists {
+			continue
+		}
+		if labelDrift.Missing {
+			drifted = append(drifted, fmt.Sprintf("%s is missing", labelDrift.Key))
+		} else {
+			drifted = append(drifted, fmt.Sprintf("%s=%s instead of %s", labelDrift.Key, labelDrift.ObservedValue, labelDrift.Value))
+		}
+	}
+	if len(drifted) == 0 {
+		return
+	}
+
+	metrics.LabelDrift.WithLabelValues(namespaceLabel.Namespace, namespaceLabel.Name).Add(float64(len(drifted)))
+	r.Recorder.Eventf(namespaceLabel, corev1.EventTypeWarning, reasonLabelsDrifted,
+		"Labels drifted on namespace %s: %s", namespaceLabel.Namespace, strings.Join(drifted, ", "))
+}
+
+// updateFailedStatus records a failed reconciliation on the NamespaceLabel
+// status and in an event, the original error is still returned by Reconcile to requeue it.
+func (r *NamespaceLabelReconciler) updateFailedStatus(ctx context.Context, namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel, reason string, err error) {
+	logger := log.FromContext(ctx)
+
+	r.Recorder.Event(namespaceLabel, corev1.EventTypeWarning, reason, err.Error())
+
+	conditions := &namespaceLabel.Status.Conditions
+	generation := namespaceLabel.Generation
+	setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionDegraded, metav1.ConditionTrue, reason, err.Error())
+	setCondition(conditions, generation, danaiodanaiov1alpha1.ConditionReady, metav1.ConditionFalse, reason, err.Error())
+	namespaceLabel.Status.ObservedGeneration = generation
+
+	if statusErr := r.Status().Update(ctx, namespaceLabel); statusErr != nil {
+		logger.Error(statusErr, "Failed to update status") // Logging the error
+	}
+}
+
 func (r *NamespaceLabelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 
 	logger := log.FromContext(ctx)
@@ -160,6 +539,13 @@ func (r *NamespaceLabelReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	if err := r.Get(ctx, types.NamespacedName{Name: req.Namespace}, &namespace); err != nil {
 		// requeue the request if we could not get the namespace
 		logger.Error(err, "Failed to get namespace", "namespace", req.Namespace) // Logging the error
+		reason := reasonGetNamespaceFailed
+		if errors.IsNotFound(err) {
+			reason = reasonNamespaceNotFound
+			// the namespace went away with the labels it was reported to hold
+			metrics.ManagedLabels.DeleteLabelValues(req.Namespace)
+		}
+		r.updateFailedStatus(ctx, &namespaceLabel, reason, err)
 		return ctrl.Result{}, err
 	}
 
@@ -168,50 +554,145 @@ func (r *NamespaceLabelReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		// The object is being deleted
 		if err := r.HandleDeletion(ctx, &namespaceLabel, &namespace); err != nil {
 			logger.Error(err, "Failed to handle deletion") // Logging the error
+			r.Recorder.Eventf(&namespace, corev1.EventTypeWarning, reasonDeletionFailed,
+				"NamespaceLabel %s failed to remove its labels: %v", namespaceLabel.Name, err)
+			r.updateFailedStatus(ctx, &namespaceLabel, reasonDeletionFailed, err)
 			return ctrl.Result{}, err
 		}
 		// Stop reconciliation as the item is being deleted
 		return ctrl.Result{}, nil
 	}
 
+	// a suspended NamespaceLabel leaves the namespace alone, its deletion is
+	// still handled above so it never hangs
+	if r.PauseAll || namespaceLabel.Spec.Suspend {
+		if err := r.updateSuspendedStatus(ctx, &namespaceLabel); err != nil {
+			logger.Error(err, "Failed to update status") // Logging the error
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// The object is not being deleted, so if it does not have our finalizer,
 	// then lets add the finalizer and update the object. This is equivalent
 	// registering our finalizer.
 	if err := r.HandleCreation(ctx, &namespaceLabel); err != nil {
 		logger.Error(err, "Failed to handle creation") // Logging the error
+		r.updateFailedStatus(ctx, &namespaceLabel, reasonFinalizerFailed, err)
 		return ctrl.Result{}, err
 	}
 
 	// update the labels
 
-	if err := r.UpdateLabels(ctx, &namespaceLabel, &namespace); err != nil {
+	merged, err := r.UpdateLabels(ctx, &namespaceLabel, &namespace)
+	if err != nil {
 		logger.Error(err, "Failed to update labels") // Logging the error
+		r.Recorder.Eventf(&namespace, corev1.EventTypeWarning, reasonUpdateLabelsFailed,
+			"NamespaceLabel %s failed to update the labels: %v", namespaceLabel.Name, err)
+		r.updateFailedStatus(ctx, &namespaceLabel, reasonUpdateLabelsFailed, err)
+		return ctrl.Result{}, err
+	}
+
+	if err := r.UpdateAnnotations(ctx, &namespaceLabel, &namespace); err != nil {
+		logger.Error(err, "Failed to update annotations") // Logging the error
+		r.Recorder.Eventf(&namespace, corev1.EventTypeWarning, reasonUpdateAnnotationsFailed,
+			"NamespaceLabel %s failed to update the annotations: %v", namespaceLabel.Name, err)
+		r.updateFailedStatus(ctx, &namespaceLabel, reasonUpdateAnnotationsFailed, err)
 		return ctrl.Result{}, err
 	}
 
-	// update the NamespaceLabel status with the total count of labels and last applied labels
-	if err := r.UpdateStatus(ctx, &namespaceLabel); err != nil {
+	if err := propagateLabels(ctx, r.Client, r.APIReader, &namespace); err != nil {
+		logger.Error(err, "Failed to propagate labels") // Logging the error
+		r.Recorder.Eventf(&namespace, corev1.EventTypeWarning, reasonPropagateLabelsFailed,
+			"NamespaceLabel %s failed to propagate the labels: %v", namespaceLabel.Name, err)
+		r.updateFailedStatus(ctx, &namespaceLabel, reasonPropagateLabelsFailed, err)
+		return ctrl.Result{}, err
+	}
+
+	// update the NamespaceLabel status with the applied labels and the conflicts it lost
+	if err := r.UpdateStatus(ctx, &namespaceLabel, &namespace, merged); err != nil {
 		logger.Error(err, "Failed to update status") // Logging the error
 		return ctrl.Result{}, err
 	}
 
+	// requeue for the next time the time-bound labels are applied or removed
+	now := r.Clock.Now()
+	if next := activeWindow(&namespaceLabel, now).next; !next.IsZero() {
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// enqueueRequestsFromNamespace enqueues the NamespaceLabels of the namespace
+// and of its descendants, which inherit from it. The NamespaceLabels of its
+// ancestors with inheritance, and those it inherited labels from before being
+// moved in the tree, are enqueued too so the labels follow the new parents.
 func (r *NamespaceLabelReconciler) enqueueRequestsFromNamespace(ctx context.Context, o client.Object) []reconcile.Request {
 	logger := log.FromContext(ctx)
 	namespace := o.(*corev1.Namespace)
 	var requests []reconcile.Request
-	var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
 
 	// List the NamespaceLabels for the given Namespace
-	if err := r.List(ctx, &namespaceLabelList, client.InNamespace(namespace.Name)); err != nil {
-		logger.Error(err, "Failed to list NamespaceLabels for namespace", "Namespace", namespace.Name)
+	requests = append(requests, r.requestsInNamespace(ctx, namespace.Name, false)...)
+
+	var namespaceList corev1.NamespaceList
+	if err := r.List(ctx, &namespaceList); err != nil {
+		logger.Error(err, "Failed to list namespaces")
+	}
+	for _, descendant := range utils.DescendantNamespaces(namespaceList.Items, namespace.Name, ParentLabel) {
+		requests = append(requests, r.requestsInNamespace(ctx, descendant.Name, false)...)
+	}
+
+	// the ancestors found before a cycle are still enqueued, the cycle itself
+	// is reported by the reconciliation
+	ancestors, err := ancestorNamespaces(ctx, r.Client, namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get the ancestors of namespace", "Namespace", namespace.Name)
+	}
+	for _, ancestor := range ancestors {
+		requests = append(requests, r.requestsInNamespace(ctx, ancestor.Name, true)...)
+	}
+
+	owners, err := utils.ParseOwners(namespace.ObjectMeta.Annotations, utils.OwnersAnnotation)
+	if err != nil {
+		logger.Error(err, "Failed to parse the owners of namespace", "Namespace", namespace.Name)
+	}
+	inherited := make(map[types.NamespacedName]struct{})
+	for _, keyOwners := range owners {
+		for _, owner := range keyOwners {
+			if kind, ownerNamespace, name := utils.ParseOwner(owner); kind == "NamespaceLabel" && ownerNamespace != "" {
+				inherited[types.NamespacedName{Namespace: ownerNamespace, Name: name}] = struct{}{}
+			}
+		}
+	}
+	for namespacedName := range inherited {
+		requests = append(requests, reconcile.Request{NamespacedName: namespacedName})
+	}
+
+	// Log the number of requests enqueued for the given Namespace
+	logger.Info("Enqueued requests for namespace", "Namespace", namespace.Name, "Number of requests", len(requests))
+
+	return requests
+}
+
+// requestsInNamespace returns the requests of the NamespaceLabels in the
+// namespace, only of those with inheritance when inheriting is set.
+func (r *NamespaceLabelReconciler) requestsInNamespace(ctx context.Context, namespace string, inheriting bool) []reconcile.Request {
+	logger := log.FromContext(ctx)
+	var requests []reconcile.Request
+	var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+
+	if err := r.List(ctx, &namespaceLabelList, client.InNamespace(namespace)); err != nil {
+		logger.Error(err, "Failed to list NamespaceLabels for namespace", "Namespace", namespace)
 		return []reconcile.Request{}
 	}
 
 	// Iterate through the NamespaceLabels and create reconcile requests for each
 	for _, namespaceLabel := range namespaceLabelList.Items {
+		if inheriting && namespaceLabel.Spec.Inheritance == nil {
+			continue
+		}
 		requests = append(requests, reconcile.Request{
 			NamespacedName: types.NamespacedName{
 				Name:      namespaceLabel.Name,
@@ -219,17 +700,105 @@ func (r *NamespaceLabelReconciler) enqueueRequestsFromNamespace(ctx context.Cont
 			},
 		})
 	}
+	return requests
+}
 
-	// Log the number of requests enqueued for the given Namespace
-	logger.Info("Enqueued requests for namespace", "Namespace", namespace.Name, "Number of requests", len(requests))
+// enqueueRequestsFromPolicy enqueues the NamespaceLabels of the namespaces
+// the NamespaceLabelPolicy selects, so they are checked against its changes.
+func (r *NamespaceLabelReconciler) enqueueRequestsFromPolicy(ctx context.Context, o client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+	policy := o.(*danaiodanaiov1alpha1.NamespaceLabelPolicy)
+	var requests []reconcile.Request
 
+	var namespaceList corev1.NamespaceList
+	if err := r.List(ctx, &namespaceList); err != nil {
+		logger.Error(err, "Failed to list namespaces")
+		return []reconcile.Request{}
+	}
+	for i := range namespaceList.Items {
+		selected, err := policy.Selects(&namespaceList.Items[i])
+		if err != nil {
+			logger.Error(err, "Failed to select the namespaces of NamespaceLabelPolicy", "NamespaceLabelPolicy", policy.Name)
+			return []reconcile.Request{}
+		}
+		if selected {
+			requests = append(requests, r.requestsInNamespace(ctx, namespaceList.Items[i].Name, false)...)
+		}
+	}
 	return requests
 }
 
+// enqueueRequestsFromSource returns a map function enqueueing the
+// NamespaceLabels whose labelsFrom reference the object, through the given index.
+func (r *NamespaceLabelReconciler) enqueueRequestsFromSource(index string) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		logger := log.FromContext(ctx)
+		var requests []reconcile.Request
+		var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+
+		if err := r.List(ctx, &namespaceLabelList, client.InNamespace(o.GetNamespace()), client.MatchingFields{index: o.GetName()}); err != nil {
+			logger.Error(err, "Failed to list NamespaceLabels for source", "Namespace", o.GetNamespace(), "Name", o.GetName())
+			return []reconcile.Request{}
+		}
+
+		for _, namespaceLabel := range namespaceLabelList.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      namespaceLabel.Name,
+					Namespace: namespaceLabel.Namespace,
+				},
+			})
+		}
+
+		return requests
+	}
+}
+
+// indexLabelsFrom indexes the NamespaceLabels by the names of the ConfigMaps
+// or, when secrets is set, the Secrets their labelsFrom reference.
+func indexLabelsFrom(secrets bool) client.IndexerFunc {
+	return func(o client.Object) []string {
+		namespaceLabel := o.(*danaiodanaiov1alpha1.NamespaceLabel)
+		var names []string
+		for _, source := range namespaceLabel.Spec.LabelsFrom {
+			if secrets && source.SecretRef != nil {
+				names = append(names, source.SecretRef.Name)
+			}
+			if !secrets && source.ConfigMapRef != nil {
+				names = append(names, source.ConfigMapRef.Name)
+			}
+		}
+		return names
+	}
+}
+
+// mergeOptions returns the options of the merges made by the reconciler.
+func (r *NamespaceLabelReconciler) mergeOptions() mergeOptions {
+	return mergeOptions{protected: r.ProtectedLabels, authorizedKeys: r.AuthorizedKeys, now: r.Clock.Now()}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *NamespaceLabelReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
+
+	ctx := context.Background()
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &danaiodanaiov1alpha1.NamespaceLabel{}, labelsFromConfigMapIndex, indexLabelsFrom(false)); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &danaiodanaiov1alpha1.NamespaceLabel{}, labelsFromSecretIndex, indexLabelsFrom(true)); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&danaiodanaiov1alpha1.NamespaceLabel{}).
 		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.enqueueRequestsFromNamespace)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.enqueueRequestsFromSource(labelsFromConfigMapIndex))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.enqueueRequestsFromSource(labelsFromSecretIndex))).
+		Watches(&danaiodanaiov1alpha1.NamespaceLabelPolicy{}, handler.EnqueueRequestsFromMapFunc(r.enqueueRequestsFromPolicy)).
 		Complete(r)
 }
@@ -7,7 +7,12 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller"
 )
 
 var _ = Describe("NamespacelabelController", func() {
@@ -61,6 +66,90 @@ var _ = Describe("NamespacelabelController", func() {
 
 		})
 
+		It("Should report the status conditions of both NamespaceLabels", func() {
+			// namespacelabel 1 was created first so it wins the shared "name" key
+			By("Waiting for namespacelabel 1 to be ready")
+			Eventually(func() bool {
+				namespaceLabel := &danaiodanaiov1alpha1.NamespaceLabel{}
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel1), namespaceLabel)
+				if err != nil {
+					return false
+				}
+
+				return namespaceLabel.Status.ObservedGeneration == namespaceLabel.Generation &&
+					meta.IsStatusConditionTrue(namespaceLabel.Status.Conditions, danaiodanaiov1alpha1.ConditionReady)
+			}, timeout, interval).Should(BeTrue(), "NamespaceLabel 1 should be ready")
+
+			By("Waiting for namespacelabel 2 to report the lost key")
+			Eventually(func() bool {
+				namespaceLabel := &danaiodanaiov1alpha1.NamespaceLabel{}
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel2), namespaceLabel)
+				if err != nil {
+					return false
+				}
+
+				if !meta.IsStatusConditionTrue(namespaceLabel.Status.Conditions, danaiodanaiov1alpha1.ConditionConflicted) ||
+					meta.IsStatusConditionTrue(namespaceLabel.Status.Conditions, danaiodanaiov1alpha1.ConditionReady) {
+					return false
+				}
+
+				for _, conflict := range namespaceLabel.Status.Conflicts {
+					if conflict.Key == "name" && conflict.Winner == namespaceLabel1.Name {
+						return true
+					}
+				}
+				return false
+			}, timeout, interval).Should(BeTrue(), "NamespaceLabel 2 should be conflicted")
+		})
+
+		It("Should write the namespace labels with server-side apply", func() {
+			By("Waiting for the operator field manager to own the labels")
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKey{Name: NamespaceLabelNamespace}, ns)
+				if err != nil {
+					return false
+				}
+
+				for _, managedFields := range ns.ManagedFields {
+					if managedFields.Manager == controller.FieldManager && managedFields.Operation == metav1.ManagedFieldsOperationApply {
+						return true
+					}
+				}
+				return false
+			}, timeout, interval).Should(BeTrue(), "Namespace should be applied by the operator field manager")
+		})
+
+		It("Should record events for the label changes", func() {
+			By("Waiting for the events of namespacelabel 1 and of the namespace")
+			Eventually(func() []string {
+				var eventList corev1.EventList
+				if err := k8sClient.List(ctx, &eventList, client.InNamespace(NamespaceLabelNamespace)); err != nil {
+					return nil
+				}
+				var reasons []string
+				for _, event := range eventList.Items {
+					if event.InvolvedObject.Kind == "NamespaceLabel" && event.InvolvedObject.Name == FirstNamespaceLabelName {
+						reasons = append(reasons, event.Reason)
+					}
+				}
+				return reasons
+			}, timeout, interval).Should(ContainElements("LabelsUpdated"))
+
+			Eventually(func() []string {
+				var eventList corev1.EventList
+				if err := k8sClient.List(ctx, &eventList); err != nil {
+					return nil
+				}
+				var reasons []string
+				for _, event := range eventList.Items {
+					if event.InvolvedObject.Kind == "Namespace" && event.InvolvedObject.Name == NamespaceLabelNamespace {
+						reasons = append(reasons, event.Reason)
+					}
+				}
+				return reasons
+			}, timeout, interval).Should(ContainElements("LabelsUpdated"))
+		})
+
 		It("Should edit NamespaceLabel 1 correctly", func() {
 			newLabels := map[string]string{
 				"newkey": "newvalue",
@@ -134,6 +223,23 @@ var _ = Describe("NamespacelabelController", func() {
 		})
 
 		It("Should delete NamespaceLabel 1 correctly", func() {
+			foreignAnnotation := "example.com/foreign-annotation"
+
+			// annotate the namespace like another controller would
+			By("Adding a foreign annotation to the namespace")
+			Eventually(func() error {
+				err := k8sClient.Get(ctx, client.ObjectKey{Name: NamespaceLabelNamespace}, ns)
+				if err != nil {
+					return err
+				}
+
+				if ns.Annotations == nil {
+					ns.Annotations = make(map[string]string)
+				}
+				ns.Annotations[foreignAnnotation] = "keep-me"
+				return k8sClient.Update(ctx, ns)
+			}, timeout, interval).Should(Succeed(), "Namespace should be annotated")
+
 			// delete namespacelabel 1
 			By("By deleting the NamespaceLabel 1")
 			Expect(k8sClient.Delete(ctx, namespaceLabel1)).Should(Succeed())
@@ -156,6 +262,11 @@ var _ = Describe("NamespacelabelController", func() {
 				}
 				return true
 			}, timeout, interval).Should(BeTrue(), "Namespace label 1 should be deleted correctly")
+
+			// check the deletion did not touch the annotations of other controllers
+			By("Checking the foreign annotation survived the deletion")
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: NamespaceLabelNamespace}, ns)).Should(Succeed())
+			Expect(ns.Annotations).To(HaveKeyWithValue(foreignAnnotation, "keep-me"))
 		})
 
 		It("Should delete NamespaceLabel 2 correctly", func() {
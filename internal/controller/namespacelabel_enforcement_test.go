@@ -0,0 +1,137 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+)
+
+var _ = Describe("NamespaceLabel enforcement", Ordered, func() {
+	ctx := context.Background()
+
+	var reportNamespaceLabel, ignoreNamespaceLabel, suspendedNamespaceLabel *danaiodanaiov1alpha1.NamespaceLabel
+
+	namespace := testNamespace(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespacelabel-enforcement-test"},
+	})
+
+	// setNamespaceLabel sets a label of the namespace like a user would
+	setNamespaceLabel := func(key, value string) {
+		current := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(namespace), current)).Should(Succeed())
+		current.Labels[key] = value
+		Expect(k8sClient.Update(ctx, current)).Should(Succeed())
+	}
+
+	It("Should report the drift without changing the namespace", func() {
+		reportNamespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "report-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels:      map[string]string{"reported": "value"},
+				Enforcement: danaiodanaiov1alpha1.ReportEnforcement,
+			},
+		}
+		Expect(k8sClient.Create(ctx, reportNamespaceLabel)).Should(Succeed())
+
+		By("Waiting for the drift to be reported")
+		Eventually(func() []danaiodanaiov1alpha1.LabelDrift {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(reportNamespaceLabel), current); err != nil {
+				return nil
+			}
+			if !meta.IsStatusConditionTrue(current.Status.Conditions, danaiodanaiov1alpha1.ConditionDrifted) {
+				return nil
+			}
+			return current.Status.Drift
+		}, timeout, interval).Should(ConsistOf(danaiodanaiov1alpha1.LabelDrift{Key: "reported", Value: "value", Missing: true}))
+
+		Consistently(namespaceLabels(namespace), "2s", interval).ShouldNot(HaveKey("reported"),
+			"The namespace should not get the reported label")
+	})
+
+	It("Should clear the drift once the namespace matches", func() {
+		setNamespaceLabel("reported", "value")
+
+		Eventually(func() bool {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(reportNamespaceLabel), current); err != nil {
+				return false
+			}
+			return meta.IsStatusConditionFalse(current.Status.Conditions, danaiodanaiov1alpha1.ConditionDrifted) &&
+				len(current.Status.Drift) == 0
+		}, timeout, interval).Should(BeTrue())
+	})
+
+	It("Should apply the labels once and leave later changes alone", func() {
+		ignoreNamespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "ignore-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels:      map[string]string{"ignored": "one"},
+				Enforcement: danaiodanaiov1alpha1.IgnoreEnforcement,
+			},
+		}
+		Expect(k8sClient.Create(ctx, ignoreNamespaceLabel)).Should(Succeed())
+
+		Eventually(namespaceLabels(namespace), timeout, interval).Should(HaveKeyWithValue("ignored", "one"))
+
+		By("Changing the label on the namespace")
+		setNamespaceLabel("ignored", "two")
+
+		Consistently(namespaceLabels(namespace), "2s", interval).Should(HaveKeyWithValue("ignored", "two"),
+			"The change to the ignored label should not be reverted")
+	})
+
+	It("Should leave the namespace alone while suspended", func() {
+		suspendedNamespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "suspended-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels:  map[string]string{"suspended": "value"},
+				Suspend: true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, suspendedNamespaceLabel)).Should(Succeed())
+
+		By("Waiting for the suspension to be reported")
+		Eventually(func() bool {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(suspendedNamespaceLabel), current); err != nil {
+				return false
+			}
+			return meta.IsStatusConditionTrue(current.Status.Conditions, danaiodanaiov1alpha1.ConditionSuspended)
+		}, timeout, interval).Should(BeTrue())
+
+		Consistently(namespaceLabels(namespace), "2s", interval).ShouldNot(HaveKey("suspended"),
+			"The namespace should not get the labels of a suspended NamespaceLabel")
+	})
+
+	It("Should apply the labels once resumed", func() {
+		current := &danaiodanaiov1alpha1.NamespaceLabel{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(suspendedNamespaceLabel), current)).Should(Succeed())
+		current.Spec.Suspend = false
+		Expect(k8sClient.Update(ctx, current)).Should(Succeed())
+
+		Eventually(namespaceLabels(namespace), timeout, interval).Should(HaveKeyWithValue("suspended", "value"))
+	})
+
+	It("Should remove the labels of a suspended NamespaceLabel once deleted", func() {
+		current := &danaiodanaiov1alpha1.NamespaceLabel{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(suspendedNamespaceLabel), current)).Should(Succeed())
+		current.Spec.Suspend = true
+		Expect(k8sClient.Update(ctx, current)).Should(Succeed())
+		Expect(k8sClient.Delete(ctx, current)).Should(Succeed())
+
+		Eventually(namespaceLabels(namespace), timeout, interval).ShouldNot(HaveKey("suspended"))
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(suspendedNamespaceLabel), &danaiodanaiov1alpha1.NamespaceLabel{})
+			return client.IgnoreNotFound(err) == nil && err != nil
+		}, timeout, interval).Should(BeTrue(), "The finalizer of a suspended NamespaceLabel should be removed")
+	})
+})
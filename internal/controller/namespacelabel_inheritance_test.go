@@ -0,0 +1,143 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller"
+)
+
+var _ = Describe("NamespaceLabel inheritance", Ordered, func() {
+	ctx := context.Background()
+
+	var tenantLabel, teamLabel *danaiodanaiov1alpha1.NamespaceLabel
+
+	// childOf returns a namespace whose parent is set by a label
+	childOf := func(name, parent string) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{controller.ParentLabel: parent},
+			},
+		}
+	}
+
+	tenant := testNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "inheritance-tenant"}})
+	team := testNamespace(childOf("inheritance-team", tenant.Name))
+	dev := testNamespace(childOf("inheritance-team-dev", team.Name))
+
+	It("Should propagate the inherited labels to every descendant", func() {
+		tenantLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-labels", Namespace: tenant.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels: map[string]string{"tenant": "acme", "tier": "gold", "billing": "central"},
+				Inheritance: &danaiodanaiov1alpha1.LabelInheritance{
+					Inherit: true,
+					Keys:    map[string]bool{"billing": false},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, tenantLabel)).Should(Succeed())
+
+		for _, namespace := range []*corev1.Namespace{team, dev} {
+			Eventually(namespaceLabels(namespace), timeout, interval).Should(SatisfyAll(
+				HaveKeyWithValue("tenant", "acme"),
+				HaveKeyWithValue("tier", "gold"),
+				Not(HaveKey("billing")),
+			))
+		}
+	})
+
+	It("Should let the labels of a child override the inherited ones", func() {
+		teamLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-labels", Namespace: team.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels:      map[string]string{"tier": "silver"},
+				Inheritance: &danaiodanaiov1alpha1.LabelInheritance{Inherit: true},
+			},
+		}
+		Expect(k8sClient.Create(ctx, teamLabel)).Should(Succeed())
+
+		Eventually(namespaceLabels(team), timeout, interval).Should(HaveKeyWithValue("tier", "silver"))
+		Eventually(namespaceLabels(dev), timeout, interval).Should(HaveKeyWithValue("tier", "silver"))
+	})
+
+	It("Should keep the inherited labels when overrides are denied", func() {
+		Eventually(func() error {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tenantLabel), current); err != nil {
+				return err
+			}
+			current.Spec.Inheritance.Overrides = danaiodanaiov1alpha1.DenyOverridePolicy
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(namespaceLabels(team), timeout, interval).Should(HaveKeyWithValue("tier", "gold"))
+		Eventually(namespaceLabels(dev), timeout, interval).Should(HaveKeyWithValue("tier", "gold"))
+		Eventually(func() []danaiodanaiov1alpha1.LabelConflict {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(teamLabel), current); err != nil {
+				return nil
+			}
+			return current.Status.Conflicts
+		}, timeout, interval).Should(ContainElement(danaiodanaiov1alpha1.LabelConflict{
+			Key: "tier", Value: "silver", Winner: "inheritance-tenant/tenant-labels", AppliedValue: "gold",
+		}))
+	})
+
+	It("Should report parents forming a cycle", func() {
+		Eventually(func() error {
+			current := &corev1.Namespace{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tenant), current); err != nil {
+				return err
+			}
+			if current.Labels == nil {
+				current.Labels = map[string]string{}
+			}
+			current.Labels[controller.ParentLabel] = dev.Name
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func() string {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(teamLabel), current); err != nil {
+				return ""
+			}
+			degraded := meta.FindStatusCondition(current.Status.Conditions, danaiodanaiov1alpha1.ConditionDegraded)
+			if degraded == nil || degraded.Status != metav1.ConditionTrue {
+				return ""
+			}
+			return degraded.Message
+		}, timeout, interval).Should(ContainSubstring("form a cycle"))
+	})
+
+	It("Should remove the inherited labels once the child leaves the tree", func() {
+		Eventually(func() error {
+			current := &corev1.Namespace{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tenant), current); err != nil {
+				return err
+			}
+			delete(current.Labels, controller.ParentLabel)
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+		Eventually(func() error {
+			current := &corev1.Namespace{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dev), current); err != nil {
+				return err
+			}
+			delete(current.Labels, controller.ParentLabel)
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(namespaceLabels(dev), timeout, interval).ShouldNot(SatisfyAny(HaveKey("tenant"), HaveKey("tier")))
+		Expect(namespaceLabels(team)()).To(HaveKeyWithValue("tenant", "acme"))
+	})
+})
@@ -0,0 +1,75 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+)
+
+var _ = Describe("NamespaceLabel labelsFrom", Ordered, func() {
+	ctx := context.Background()
+
+	var configMap *corev1.ConfigMap
+
+	namespace := testNamespace(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespacelabel-labelsfrom-test"},
+	})
+
+	BeforeAll(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci-labels", Namespace: namespace.Name},
+			Data: map[string]string{
+				"team-name":  "apps",
+				"team-tier":  "backend",
+				"unrelated":  "value",
+				"inline-key": "from-configmap",
+			},
+		}
+		Expect(k8sClient.Create(ctx, configMap)).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, configMap))).Should(Succeed())
+	})
+
+	It("Should apply the ConfigMap entries matching the prefix along with the inline labels", func() {
+		namespaceLabel := &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "labelsfrom-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels: map[string]string{"inline-key": "inline"},
+				LabelsFrom: []danaiodanaiov1alpha1.LabelsSource{
+					{ConfigMapRef: &corev1.LocalObjectReference{Name: configMap.Name}, Prefix: "team-"},
+					{ConfigMapRef: &corev1.LocalObjectReference{Name: "missing"}, Optional: true},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespaceLabel)).Should(Succeed())
+
+		Eventually(namespaceLabels(namespace), timeout, interval).Should(SatisfyAll(
+			HaveKeyWithValue("team-name", "apps"),
+			HaveKeyWithValue("team-tier", "backend"),
+			HaveKeyWithValue("inline-key", "inline"),
+			Not(HaveKey("unrelated")),
+		))
+	})
+
+	It("Should update the namespace when the ConfigMap changes", func() {
+		current := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(configMap), current)).Should(Succeed())
+		current.Data["team-name"] = "platform"
+		delete(current.Data, "team-tier")
+		Expect(k8sClient.Update(ctx, current)).Should(Succeed())
+
+		Eventually(namespaceLabels(namespace), timeout, interval).Should(SatisfyAll(
+			HaveKeyWithValue("team-name", "platform"),
+			Not(HaveKey("team-tier")),
+		))
+	})
+})
@@ -0,0 +1,90 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+)
+
+var _ = Describe("NamespaceLabel policies", Ordered, func() {
+	ctx := context.Background()
+
+	var namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel
+	var policy *danaiodanaiov1alpha1.NamespaceLabelPolicy
+
+	namespace := testNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "policy-test"}})
+
+	// compliantOf returns the Compliant condition of the NamespaceLabel
+	compliantOf := func() *metav1.Condition {
+		current := &danaiodanaiov1alpha1.NamespaceLabel{}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel), current); err != nil {
+			return nil
+		}
+		return meta.FindStatusCondition(current.Status.Conditions, danaiodanaiov1alpha1.ConditionCompliant)
+	}
+
+	BeforeAll(func() {
+		namespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy-labels", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels: map[string]string{"team": "a", "env": "staging"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespaceLabel)).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		if policy != nil {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, policy))).Should(Succeed())
+		}
+	})
+
+	It("Should be compliant without policies", func() {
+		Eventually(func() metav1.ConditionStatus {
+			if compliant := compliantOf(); compliant != nil {
+				return compliant.Status
+			}
+			return ""
+		}, timeout, interval).Should(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should flag the existing NamespaceLabels once a policy forbids their labels", func() {
+		policy = &danaiodanaiov1alpha1.NamespaceLabelPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy-test"},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelPolicySpec{
+				NamespaceSelector: danaiodanaiov1alpha1.NamespaceSelector{Names: []string{namespace.Name}},
+				Values:            []danaiodanaiov1alpha1.LabelValueConstraint{{Key: "env", Values: []string{"dev", "prod"}}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+
+		Eventually(compliantOf, timeout, interval).Should(SatisfyAll(
+			HaveField("Status", metav1.ConditionFalse),
+			HaveField("Message", ContainSubstring("spec.labels[env]")),
+		))
+
+		// the labels are still applied, the policy is only enforced on admission
+		Expect(namespaceLabels(namespace)()).To(HaveKeyWithValue("env", "staging"))
+	})
+
+	It("Should be compliant again once the policy allows the labels", func() {
+		Eventually(func() error {
+			current := &danaiodanaiov1alpha1.NamespaceLabelPolicy{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(policy), current); err != nil {
+				return err
+			}
+			current.Spec.Values[0].Values = append(current.Spec.Values[0].Values, "staging")
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(compliantOf, timeout, interval).Should(HaveField("Status", metav1.ConditionTrue))
+	})
+})
@@ -0,0 +1,81 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+var _ = Describe("NamespaceLabel propagation", Ordered, func() {
+	ctx := context.Background()
+
+	var service *corev1.Service
+	var namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel
+
+	namespace := testNamespace(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespacelabel-propagation-test"},
+	})
+
+	// serviceLabels returns the labels of the service
+	serviceLabels := func() map[string]string {
+		current := &corev1.Service{}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(service), current); err != nil {
+			return nil
+		}
+		return current.Labels
+	}
+
+	BeforeAll(func() {
+		service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: namespace.Name,
+				Labels:    map[string]string{"app": "web"},
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 80}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, service)).Should(Succeed())
+	})
+
+	It("Should copy the selected managed labels onto the existing objects", func() {
+		namespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "propagated-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels: map[string]string{"cost-center": "1234", "team": "apps"},
+				PropagateTo: []danaiodanaiov1alpha1.LabelPropagation{
+					{Kind: danaiodanaiov1alpha1.ServicesPropagationKind, Keys: []string{"cost-center"}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespaceLabel)).Should(Succeed())
+
+		Eventually(serviceLabels, timeout, interval).Should(Equal(map[string]string{"app": "web", "cost-center": "1234"}))
+
+		current := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(service), current)).Should(Succeed())
+		Expect(current.Annotations).To(HaveKey(utils.OwnersAnnotation))
+	})
+
+	It("Should remove the labels that are not propagated anymore", func() {
+		Eventually(func() error {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel), current); err != nil {
+				return err
+			}
+			current.Spec.PropagateTo = nil
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(serviceLabels, timeout, interval).Should(Equal(map[string]string{"app": "web"}))
+	})
+})
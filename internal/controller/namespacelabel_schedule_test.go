@@ -0,0 +1,156 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller"
+)
+
+var _ = Describe("Time-bound NamespaceLabel", Ordered, func() {
+	ctx := context.Background()
+
+	var namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel
+
+	namespace := testNamespace(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespacelabel-schedule-test"},
+	})
+
+	// activeReason returns the reason of the Active condition of the NamespaceLabel
+	activeReason := func() string {
+		current := &danaiodanaiov1alpha1.NamespaceLabel{}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel), current); err != nil {
+			return ""
+		}
+		active := meta.FindStatusCondition(current.Status.Conditions, danaiodanaiov1alpha1.ConditionActive)
+		if active == nil {
+			return ""
+		}
+		return active.Reason
+	}
+
+	It("Should not apply the labels before activeFrom", func() {
+		activeFrom := metav1.NewTime(time.Now().Add(5 * time.Second))
+		namespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "maintenance-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels:     map[string]string{"maintenance": "true"},
+				ActiveFrom: &activeFrom,
+				TTL:        &metav1.Duration{Duration: 5 * time.Second},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespaceLabel)).Should(Succeed())
+
+		Eventually(activeReason, timeout, interval).Should(Equal("Scheduled"))
+		Expect(namespaceLabels(namespace)()).NotTo(HaveKey("maintenance"))
+	})
+
+	It("Should apply the labels once active", func() {
+		Eventually(namespaceLabels(namespace), timeout, interval).Should(HaveKeyWithValue("maintenance", "true"))
+		Expect(activeReason()).To(Equal("Active"))
+	})
+
+	It("Should remove the labels once expired", func() {
+		Eventually(namespaceLabels(namespace), timeout, interval).ShouldNot(HaveKey("maintenance"))
+		Eventually(activeReason, timeout, interval).Should(Equal("Expired"))
+	})
+})
+
+var _ = Describe("Scheduled NamespaceLabel", Ordered, func() {
+	ctx := context.Background()
+
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	// the reconciler is driven by the test, on a clock the test moves
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2023, time.July, 14, 17, 0, 0, 0, paris))
+	var reconciler *controller.NamespaceLabelReconciler
+
+	var namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel
+
+	namespace := testNamespace(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespacelabel-cron-test"},
+	})
+
+	// reconcile reconciles the NamespaceLabel until its finalizer is set
+	reconcile := func() ctrl.Result {
+		request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(namespaceLabel)}
+		result, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		result, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	// status returns the status of the NamespaceLabel
+	status := func() danaiodanaiov1alpha1.NamespaceLabelStatus {
+		current := &danaiodanaiov1alpha1.NamespaceLabel{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel), current)).Should(Succeed())
+		return current.Status
+	}
+
+	BeforeAll(func() {
+		reconciler = &controller.NamespaceLabelReconciler{
+			Client:    k8sClient,
+			Scheme:    k8sClient.Scheme(),
+			Recorder:  record.NewFakeRecorder(100),
+			Clock:     fakeClock,
+			APIReader: k8sClient,
+		}
+
+		namespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "freeze-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels: map[string]string{"deploy-freeze": "true"},
+				Schedules: []danaiodanaiov1alpha1.LabelSchedule{{
+					Cron:     "0 18 * * 5",
+					Duration: metav1.Duration{Duration: 60 * time.Hour},
+					TimeZone: "Europe/Paris",
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespaceLabel)).Should(Succeed())
+	})
+
+	It("Should requeue at the start of the next window", func() {
+		Expect(reconcile().RequeueAfter).To(Equal(time.Hour))
+		Expect(namespaceLabels(namespace)()).NotTo(HaveKey("deploy-freeze"))
+
+		current := status()
+		Expect(current.CurrentWindow).To(BeNil())
+		Expect(current.NextTransition.Time).To(BeTemporally("==", time.Date(2023, time.July, 14, 18, 0, 0, 0, paris)))
+		Expect(meta.FindStatusCondition(current.Conditions, danaiodanaiov1alpha1.ConditionActive).Reason).To(Equal("OutsideSchedule"))
+	})
+
+	It("Should apply the labels within the window", func() {
+		fakeClock.SetTime(time.Date(2023, time.July, 14, 18, 0, 0, 0, paris))
+
+		Expect(reconcile().RequeueAfter).To(Equal(60 * time.Hour))
+		Expect(namespaceLabels(namespace)()).To(HaveKeyWithValue("deploy-freeze", "true"))
+
+		current := status()
+		Expect(current.CurrentWindow).NotTo(BeNil())
+		Expect(current.CurrentWindow.Start.Time).To(BeTemporally("==", time.Date(2023, time.July, 14, 18, 0, 0, 0, paris)))
+		Expect(current.CurrentWindow.End.Time).To(BeTemporally("==", time.Date(2023, time.July, 17, 6, 0, 0, 0, paris)))
+		Expect(meta.FindStatusCondition(current.Conditions, danaiodanaiov1alpha1.ConditionActive).Reason).To(Equal("Active"))
+	})
+
+	It("Should remove the labels once the window closes", func() {
+		fakeClock.SetTime(time.Date(2023, time.July, 17, 6, 0, 0, 0, paris))
+
+		Expect(reconcile().RequeueAfter).To(Equal(4*24*time.Hour + 12*time.Hour))
+		Expect(namespaceLabels(namespace)()).NotTo(HaveKey("deploy-freeze"))
+		Expect(status().CurrentWindow).To(BeNil())
+	})
+})
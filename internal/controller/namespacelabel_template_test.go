@@ -0,0 +1,85 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+)
+
+var _ = Describe("NamespaceLabel templates", Ordered, func() {
+	ctx := context.Background()
+
+	var namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel
+
+	namespace := testNamespace(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "namespacelabel-template-test",
+			Annotations: map[string]string{"owner": "jane"},
+		},
+	})
+
+	It("Should render the templated labels for the namespace", func() {
+		namespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "template-namespacelabel", Namespace: namespace.Name},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels: map[string]string{
+					"name":  "{{ .Namespace.Name }}",
+					"owner": `{{ index .Namespace.Annotations "owner" }}`,
+					"team":  "apps",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespaceLabel)).Should(Succeed())
+
+		Eventually(namespaceLabels(namespace), timeout, interval).Should(SatisfyAll(
+			HaveKeyWithValue("name", namespace.Name),
+			HaveKeyWithValue("owner", "jane"),
+			HaveKeyWithValue("team", "apps"),
+		))
+
+		Eventually(func() map[string]string {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel), current); err != nil {
+				return nil
+			}
+			return current.Status.RenderedLabels
+		}, timeout, interval).Should(Equal(map[string]string{"name": namespace.Name, "owner": "jane"}))
+	})
+
+	It("Should render the labels again when the namespace changes", func() {
+		current := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(namespace), current)).Should(Succeed())
+		current.Annotations["owner"] = "john"
+		Expect(k8sClient.Update(ctx, current)).Should(Succeed())
+
+		Eventually(namespaceLabels(namespace), timeout, interval).Should(HaveKeyWithValue("owner", "john"))
+	})
+
+	It("Should report the labels that render to invalid values", func() {
+		current := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(namespace), current)).Should(Succeed())
+		current.Annotations["owner"] = "not a label value!"
+		Expect(k8sClient.Update(ctx, current)).Should(Succeed())
+
+		Eventually(func() string {
+			current := &danaiodanaiov1alpha1.NamespaceLabel{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespaceLabel), current); err != nil {
+				return ""
+			}
+			ready := meta.FindStatusCondition(current.Status.Conditions, danaiodanaiov1alpha1.ConditionReady)
+			if ready == nil {
+				return ""
+			}
+			return ready.Reason
+		}, timeout, interval).Should(Equal("TemplateFailed"))
+		Expect(namespaceLabels(namespace)()).NotTo(HaveKey("owner"))
+	})
+})
@@ -0,0 +1,195 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+// propagationKinds are the kinds of the objects of every propagation kind, in
+// the order they are swept.
+var propagationKinds = []struct {
+	kind danaiodanaiov1alpha1.PropagationKind
+	gvk  schema.GroupVersionKind
+}{
+	{danaiodanaiov1alpha1.PodsPropagationKind, corev1.SchemeGroupVersion.WithKind("Pod")},
+	{danaiodanaiov1alpha1.DeploymentsPropagationKind, appsv1.SchemeGroupVersion.WithKind("Deployment")},
+	{danaiodanaiov1alpha1.StatefulSetsPropagationKind, appsv1.SchemeGroupVersion.WithKind("StatefulSet")},
+	{danaiodanaiov1alpha1.ServicesPropagationKind, corev1.SchemeGroupVersion.WithKind("Service")},
+	{danaiodanaiov1alpha1.PersistentVolumeClaimsPropagationKind, corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim")},
+}
+
+//+kubebuilder:rbac:groups="",resources=pods;services;persistentvolumeclaims,verbs=get;list;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;patch
+
+// propagateLabels propagates the labels of the namespace to the objects in it,
+// following the propagateTo of its NamespaceLabels. The objects holding labels
+// that are not propagated anymore are cleaned up, so the kinds the
+// NamespaceLabels propagated to before are swept too. The other kinds are
+// not listed at all.
+//
+// The objects are listed through the reader, which should read from the API
+// server: listing them through the cached client would start an informer
+// caching the metadata of every object of the kind in the cluster. Each
+// propagation costs a list of the namespace per swept kind instead.
+func propagateLabels(ctx context.Context, c client.Client, reader client.Reader, namespace *corev1.Namespace) error {
+	var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+	if err := c.List(ctx, &namespaceLabelList, client.InNamespace(namespace.Name)); err != nil {
+		return err
+	}
+
+	swept := make(map[danaiodanaiov1alpha1.PropagationKind]struct{})
+	for i := range namespaceLabelList.Items {
+		namespaceLabel := &namespaceLabelList.Items[i]
+		for _, kind := range namespaceLabel.Status.PropagatedKinds {
+			swept[kind] = struct{}{}
+		}
+		if namespaceLabel.ObjectMeta.DeletionTimestamp.IsZero() {
+			for _, kind := range specPropagationKinds(namespaceLabel) {
+				swept[kind] = struct{}{}
+			}
+		}
+	}
+
+	var errs []error
+	for _, propagation := range propagationKinds {
+		if _, exists := swept[propagation.kind]; !exists {
+			continue
+		}
+		labels, owners, err := utils.PropagatedLabels(namespace, namespaceLabelList.Items, propagation.kind)
+		if err != nil {
+			return err
+		}
+
+		// only the metadata is read and written, whatever the kind
+		objectList := &metav1.PartialObjectMetadataList{}
+		objectList.SetGroupVersionKind(propagation.gvk.GroupVersion().WithKind(propagation.gvk.Kind + "List"))
+		if err := reader.List(ctx, objectList, client.InNamespace(namespace.Name)); err != nil {
+			errs = append(errs, fmt.Errorf("unable to list %s: %w", propagation.kind, err))
+			continue
+		}
+		for i := range objectList.Items {
+			object := &objectList.Items[i]
+			object.SetGroupVersionKind(propagation.gvk)
+			if err := patchPropagatedLabels(ctx, c, object, labels, owners); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("%s %s: %w", propagation.gvk.Kind, object.Name, err))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// specPropagationKinds returns the kinds the spec of the NamespaceLabel
+// propagates its labels to, in the order they are swept.
+func specPropagationKinds(namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel) []danaiodanaiov1alpha1.PropagationKind {
+	var kinds []danaiodanaiov1alpha1.PropagationKind
+	for _, propagation := range propagationKinds {
+		for _, labelPropagation := range namespaceLabel.Spec.PropagateTo {
+			if labelPropagation.Kind == propagation.kind {
+				kinds = append(kinds, propagation.kind)
+				break
+			}
+		}
+	}
+	return kinds
+}
+
+// patchPropagatedLabels patches the propagated labels of an object, objects
+// already up to date are not written.
+func patchPropagatedLabels(ctx context.Context, c client.Client, object *metav1.PartialObjectMetadata, labels map[string]string, owners utils.Owners) error {
+	desired := object.DeepCopy()
+	if err := utils.SetPropagatedLabels(desired, labels, owners); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(object.ObjectMeta.Labels, desired.ObjectMeta.Labels) &&
+		equality.Semantic.DeepEqual(object.ObjectMeta.Annotations, desired.ObjectMeta.Annotations) {
+		return nil
+	}
+	return c.Patch(ctx, desired, client.MergeFrom(object), client.FieldOwner(FieldManager))
+}
+
+// PropagationSweeper periodically propagates the labels of the namespaces to
+// the objects in them, labelling the objects the reconciliation did not see
+// yet, such as the ones created since.
+type PropagationSweeper struct {
+	Client client.Client
+
+	// APIReader lists the objects from the API server, see propagateLabels.
+	APIReader client.Reader
+
+	// Interval between two sweeps.
+	Interval time.Duration
+}
+
+// Start implements manager.Runnable, sweeping until the context is done.
+func (s *PropagationSweeper) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, s.sweep, s.Interval)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the
+// leader writes to the objects.
+func (s *PropagationSweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// sweep propagates the labels of every namespace with a NamespaceLabel
+// propagating them.
+func (s *PropagationSweeper) sweep(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("propagation-sweeper")
+
+	var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+	if err := s.Client.List(ctx, &namespaceLabelList); err != nil {
+		logger.Error(err, "Failed to list NamespaceLabels")
+		return
+	}
+
+	namespaces := make(map[string]struct{})
+	for _, namespaceLabel := range namespaceLabelList.Items {
+		if len(namespaceLabel.Spec.PropagateTo) > 0 {
+			namespaces[namespaceLabel.Namespace] = struct{}{}
+		}
+	}
+
+	for name := range namespaces {
+		namespace := &corev1.Namespace{}
+		if err := s.Client.Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+			logger.Error(err, "Failed to get namespace", "namespace", name)
+			continue
+		}
+		if err := propagateLabels(ctx, s.Client, s.APIReader, namespace); err != nil {
+			logger.Error(err, "Failed to propagate the labels", "namespace", name)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+)
+
+const (
+	// RequiredLabelsName is the name of the NamespaceLabel created in the new
+	// namespaces missing labels required with the Default mode.
+	RequiredLabelsName = "required-labels"
+
+	// DefaultedLabelsAnnotation records on a NamespaceLabel the default values
+	// it was created with, it needs attention while it still sets them.
+	// Removing the annotation accepts the default values.
+	DefaultedLabelsAnnotation = "namespacelabeler.dana.io/defaulted-labels"
+
+	reasonRequiredLabelsDefaulted = "RequiredLabelsDefaulted"
+	reasonDefaultedLabels         = "DefaultedLabels"
+	reasonLabelsSet               = "LabelsSet"
+)
+
+// RequiredLabelsReconciler sets the labels required on the new namespaces by
+// the NamespaceLabelPolicies with the Default mode, through a NamespaceLabel
+// holding their default values. The namespaces created before a policy are
+// left alone, as are the keys already set by a NamespaceLabel.
+type RequiredLabelsReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=dana.io.dana.io,resources=namespacelabels,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=dana.io.dana.io,resources=namespacelabels/keys,verbs=set
+//+kubebuilder:rbac:groups=dana.io.dana.io,resources=namespacelabelpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile creates or updates the NamespaceLabel setting the default values
+// of the labels the namespace is missing.
+func (r *RequiredLabelsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var namespace corev1.Namespace
+	if err := r.Get(ctx, req.NamespacedName, &namespace); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !namespace.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	missing, err := r.missingLabels(ctx, &namespace)
+	if err != nil {
+		logger.Error(err, "Failed to find the missing required labels")
+		return ctrl.Result{}, err
+	}
+	if len(missing) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	namespaceLabel := &danaiodanaiov1alpha1.NamespaceLabel{}
+	err = r.Get(ctx, client.ObjectKey{Namespace: namespace.Name, Name: RequiredLabelsName}, namespaceLabel)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	exists := err == nil
+	if !exists {
+		namespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: RequiredLabelsName, Namespace: namespace.Name},
+		}
+	}
+
+	defaulted := make(map[string]string)
+	if value, ok := namespaceLabel.ObjectMeta.Annotations[DefaultedLabelsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &defaulted); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if namespaceLabel.Spec.Labels == nil {
+		namespaceLabel.Spec.Labels = make(map[string]string)
+	}
+	for key, value := range missing {
+		namespaceLabel.Spec.Labels[key] = value
+		defaulted[key] = value
+	}
+	value, err := json.Marshal(defaulted)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if namespaceLabel.ObjectMeta.Annotations == nil {
+		namespaceLabel.ObjectMeta.Annotations = make(map[string]string)
+	}
+	namespaceLabel.ObjectMeta.Annotations[DefaultedLabelsAnnotation] = string(value)
+
+	if exists {
+		err = r.Update(ctx, namespaceLabel)
+	} else {
+		err = r.Create(ctx, namespaceLabel)
+	}
+	if err != nil {
+		logger.Error(err, "Failed to set the default values of the required labels", "Namespace", namespace.Name)
+		return ctrl.Result{}, err
+	}
+
+	keys := make([]string, 0, len(missing))
+	for key := range missing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	r.Recorder.Eventf(&namespace, corev1.EventTypeNormal, reasonRequiredLabelsDefaulted,
+		"NamespaceLabel %s sets the default values of the required labels %s", RequiredLabelsName, strings.Join(keys, ", "))
+	return ctrl.Result{}, nil
+}
+
+// missingLabels returns the default values of the labels required with the
+// Default mode that neither the namespace nor its NamespaceLabels set.
+func (r *RequiredLabelsReconciler) missingLabels(ctx context.Context, namespace *corev1.Namespace) (map[string]string, error) {
+	var policyList danaiodanaiov1alpha1.NamespaceLabelPolicyList
+	if err := r.List(ctx, &policyList); err != nil {
+		return nil, err
+	}
+
+	missing := make(map[string]string)
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+		if policy.Spec.Namespaces == nil || policy.Spec.Namespaces.Mode != danaiodanaiov1alpha1.DefaultNamespaceAdmission {
+			continue
+		}
+		// the namespaces created before the policy were not required the labels
+		if namespace.CreationTimestamp.Before(&policy.CreationTimestamp) {
+			continue
+		}
+		selected, err := policy.Selects(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			continue
+		}
+		for _, key := range policy.MissingNamespaceLabels(namespace) {
+			if _, exists := missing[key]; !exists {
+				missing[key] = policy.Spec.Namespaces.Defaults[key]
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	// the labels of the NamespaceLabels may not be applied yet
+	var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+	if err := r.List(ctx, &namespaceLabelList, client.InNamespace(namespace.Name)); err != nil {
+		return nil, err
+	}
+	for _, namespaceLabel := range namespaceLabelList.Items {
+		for key := range namespaceLabel.Spec.Labels {
+			delete(missing, key)
+		}
+	}
+	return missing, nil
+}
+
+// updateAttentionStatus records whether a NamespaceLabel created with the
+// default values of the required labels still sets them.
+func updateAttentionStatus(namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel) error {
+	conditions := &namespaceLabel.Status.Conditions
+	value, exists := namespaceLabel.ObjectMeta.Annotations[DefaultedLabelsAnnotation]
+	if !exists {
+		meta.RemoveStatusCondition(conditions, danaiodanaiov1alpha1.ConditionNeedsAttention)
+		return nil
+	}
+
+	defaulted := make(map[string]string)
+	if err := json.Unmarshal([]byte(value), &defaulted); err != nil {
+		return err
+	}
+	var pending []string
+	for key, defaultValue := range defaulted {
+		if current, ok := namespaceLabel.Spec.Labels[key]; ok && current == defaultValue {
+			pending = append(pending, key)
+		}
+	}
+	sort.Strings(pending)
+
+	if len(pending) == 0 {
+		setCondition(conditions, namespaceLabel.Generation, danaiodanaiov1alpha1.ConditionNeedsAttention, metav1.ConditionFalse, reasonLabelsSet, "")
+		return nil
+	}
+	setCondition(conditions, namespaceLabel.Generation, danaiodanaiov1alpha1.ConditionNeedsAttention, metav1.ConditionTrue, reasonDefaultedLabels,
+		fmt.Sprintf("labels %s still have the default values of the required labels, set them or remove the %s annotation to keep them",
+			strings.Join(pending, ", "), DefaultedLabelsAnnotation))
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RequiredLabelsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("requiredlabels").
+		For(&corev1.Namespace{}).
+		Complete(r)
+}
@@ -0,0 +1,124 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller"
+)
+
+var _ = Describe("Required namespace labels", Ordered, func() {
+	ctx := context.Background()
+
+	var denyPolicy, defaultPolicy *danaiodanaiov1alpha1.NamespaceLabelPolicy
+	var namespace *corev1.Namespace
+
+	// requiredLabels returns the NamespaceLabel created in the namespace
+	requiredLabels := func() *danaiodanaiov1alpha1.NamespaceLabel {
+		current := &danaiodanaiov1alpha1.NamespaceLabel{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace.Name, Name: controller.RequiredLabelsName}, current); err != nil {
+			return nil
+		}
+		return current
+	}
+
+	// needsAttention returns the NeedsAttention condition of the NamespaceLabel
+	needsAttention := func() metav1.ConditionStatus {
+		current := requiredLabels()
+		if current == nil {
+			return ""
+		}
+		if condition := meta.FindStatusCondition(current.Status.Conditions, danaiodanaiov1alpha1.ConditionNeedsAttention); condition != nil {
+			return condition.Status
+		}
+		return ""
+	}
+
+	BeforeAll(func() {
+		denyPolicy = &danaiodanaiov1alpha1.NamespaceLabelPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "required-deny"},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelPolicySpec{
+				NamespaceSelector: danaiodanaiov1alpha1.NamespaceSelector{Names: []string{"required-deny-*"}},
+				Namespaces: &danaiodanaiov1alpha1.NamespaceRequirements{
+					RequiredKeys: []string{"team", "cost-center", "env"},
+					Mode:         danaiodanaiov1alpha1.DenyNamespaceAdmission,
+				},
+			},
+		}
+		defaultPolicy = &danaiodanaiov1alpha1.NamespaceLabelPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "required-default"},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelPolicySpec{
+				NamespaceSelector: danaiodanaiov1alpha1.NamespaceSelector{Names: []string{"required-default-*"}},
+				Namespaces: &danaiodanaiov1alpha1.NamespaceRequirements{
+					RequiredKeys: []string{"team", "env"},
+					Mode:         danaiodanaiov1alpha1.DefaultNamespaceAdmission,
+					Defaults:     map[string]string{"team": "unassigned", "env": "dev"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, denyPolicy)).Should(Succeed())
+		Expect(k8sClient.Create(ctx, defaultPolicy)).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		if namespace != nil {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, namespace))).Should(Succeed())
+		}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, denyPolicy))).Should(Succeed())
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, defaultPolicy))).Should(Succeed())
+	})
+
+	It("Should deny the new namespaces missing required labels", func() {
+		denied := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "required-deny-a",
+			Labels: map[string]string{"team": "a"},
+		}}
+		Eventually(func() bool {
+			err := k8sClient.Create(ctx, denied)
+			if err == nil {
+				Expect(k8sClient.Delete(ctx, denied)).Should(Succeed())
+			}
+			return apierrors.IsInvalid(err)
+		}, timeout, interval).Should(BeTrue())
+	})
+
+	It("Should set the default values of the missing labels", func() {
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "required-default-a",
+			Labels: map[string]string{"team": "a"},
+		}}
+		Expect(k8sClient.Create(ctx, namespace)).Should(Succeed())
+
+		Eventually(func() map[string]string {
+			current := &corev1.Namespace{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespace), current); err != nil {
+				return nil
+			}
+			return current.Labels
+		}, timeout, interval).Should(SatisfyAll(HaveKeyWithValue("team", "a"), HaveKeyWithValue("env", "dev")))
+		Expect(requiredLabels().Spec.Labels).To(Equal(map[string]string{"env": "dev"}))
+		Eventually(needsAttention, timeout, interval).Should(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should not need attention once the default values are changed", func() {
+		Eventually(func() error {
+			current := requiredLabels()
+			if current == nil {
+				return apierrors.NewNotFound(danaiodanaiov1alpha1.GroupVersion.WithResource("namespacelabels").GroupResource(), controller.RequiredLabelsName)
+			}
+			current.Spec.Labels["env"] = "prod"
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(needsAttention, timeout, interval).Should(Equal(metav1.ConditionFalse))
+	})
+})
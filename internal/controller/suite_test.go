@@ -32,6 +32,7 @@ import (
 	"context"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
@@ -60,6 +61,45 @@ const (
 	interval = time.Millisecond * 250
 )
 
+// testNamespace creates the namespace before the specs of the ordered
+// container it is called in, and deletes it with its NamespaceLabels after them.
+func testNamespace(namespace *corev1.Namespace) *corev1.Namespace {
+	BeforeAll(func() {
+		Expect(k8sClient.Create(context.Background(), namespace)).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		ctx := context.Background()
+		Expect(k8sClient.DeleteAllOf(ctx, &danaiodanaiov1alpha1.NamespaceLabel{}, client.InNamespace(namespace.Name))).Should(Succeed())
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, namespace))).Should(Succeed())
+	})
+	return namespace
+}
+
+// namespaceLabels returns a getter of the current labels of the namespace, for
+// Eventually and Consistently.
+func namespaceLabels(namespace *corev1.Namespace) func() map[string]string {
+	return func() map[string]string {
+		current := &corev1.Namespace{}
+		if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(namespace), current); err != nil {
+			return nil
+		}
+		return current.Labels
+	}
+}
+
+// namespaceAnnotations returns a getter of the current annotations of the
+// namespace, for Eventually and Consistently.
+func namespaceAnnotations(namespace *corev1.Namespace) func() map[string]string {
+	return func() map[string]string {
+		current := &corev1.Namespace{}
+		if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(namespace), current); err != nil {
+			return nil
+		}
+		return current.Annotations
+	}
+}
+
 func TestControllers(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LabelChange is a change made to a single label, Old is empty for added
+// labels and New is empty for removed labels.
+type LabelChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// LabelDiff describes the changes made to a set of labels, sorted by key.
+type LabelDiff struct {
+	Added   []LabelChange
+	Changed []LabelChange
+	Removed []LabelChange
+}
+
+// Utility function to compute the changes made to the labels, from before to after
+func DiffLabels(before, after map[string]string) LabelDiff {
+	var diff LabelDiff
+	for key, value := range after {
+		oldValue, exists := before[key]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, LabelChange{Key: key, New: value})
+		case oldValue != value:
+			diff.Changed = append(diff.Changed, LabelChange{Key: key, Old: oldValue, New: value})
+		}
+	}
+	for key, value := range before {
+		if _, exists := after[key]; !exists {
+			diff.Removed = append(diff.Removed, LabelChange{Key: key, Old: value})
+		}
+	}
+
+	for _, changes := range [][]LabelChange{diff.Added, diff.Changed, diff.Removed} {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Key < changes[j].Key
+		})
+	}
+	return diff
+}
+
+// Empty returns whether no label was changed.
+func (d LabelDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// String describes the changes, e.g. "added team=apps, changed env: dev -> prod, removed tier=web".
+func (d LabelDiff) String() string {
+	var parts []string
+	if len(d.Added) > 0 {
+		parts = append(parts, "added "+joinChanges(d.Added, func(c LabelChange) string {
+			return fmt.Sprintf("%s=%s", c.Key, c.New)
+		}))
+	}
+	if len(d.Changed) > 0 {
+		parts = append(parts, "changed "+joinChanges(d.Changed, func(c LabelChange) string {
+			return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+		}))
+	}
+	if len(d.Removed) > 0 {
+		parts = append(parts, "removed "+joinChanges(d.Removed, func(c LabelChange) string {
+			return fmt.Sprintf("%s=%s", c.Key, c.Old)
+		}))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinChanges(changes []LabelChange, describe func(LabelChange) string) string {
+	descriptions := make([]string, 0, len(changes))
+	for _, change := range changes {
+		descriptions = append(descriptions, describe(change))
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,29 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+var _ = Describe("DiffLabels", func() {
+	It("should describe the added, changed and removed labels", func() {
+		diff := utils.DiffLabels(
+			map[string]string{"env": "dev", "tier": "web", "same": "value"},
+			map[string]string{"env": "prod", "team": "apps", "same": "value"},
+		)
+
+		Expect(diff.Added).To(Equal([]utils.LabelChange{{Key: "team", New: "apps"}}))
+		Expect(diff.Changed).To(Equal([]utils.LabelChange{{Key: "env", Old: "dev", New: "prod"}}))
+		Expect(diff.Removed).To(Equal([]utils.LabelChange{{Key: "tier", Old: "web"}}))
+		Expect(diff.String()).To(Equal("added team=apps, changed env: dev -> prod, removed tier=web"))
+	})
+
+	It("should be empty when nothing changed", func() {
+		diff := utils.DiffLabels(map[string]string{"env": "dev"}, map[string]string{"env": "dev"})
+
+		Expect(diff.Empty()).To(BeTrue())
+		Expect(diff.String()).To(BeEmpty())
+	})
+})
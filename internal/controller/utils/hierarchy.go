@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Utility function to get the parent of a namespace from the given label key,
+// or else from the annotation with the same key, empty for a root namespace
+func ParentNamespace(namespace *corev1.Namespace, key string) string {
+	if parent, exists := namespace.ObjectMeta.Labels[key]; exists {
+		return parent
+	}
+	return namespace.ObjectMeta.Annotations[key]
+}
+
+// Utility function to get the descendants of the named namespace among the
+// given namespaces, nearest first. Parents forming a cycle are walked once, and
+// the namespace is never its own descendant.
+func DescendantNamespaces(namespaces []corev1.Namespace, name, key string) []*corev1.Namespace {
+	children := make(map[string][]*corev1.Namespace)
+	for i := range namespaces {
+		parent := ParentNamespace(&namespaces[i], key)
+		if parent != "" {
+			children[parent] = append(children[parent], &namespaces[i])
+		}
+	}
+	for parent := range children {
+		sort.Slice(children[parent], func(i, j int) bool {
+			return children[parent][i].Name < children[parent][j].Name
+		})
+	}
+
+	var descendants []*corev1.Namespace
+	visited := map[string]struct{}{name: {}}
+	queue := []string{name}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, child := range children[parent] {
+			if _, exists := visited[child.Name]; exists {
+				continue
+			}
+			visited[child.Name] = struct{}{}
+			descendants = append(descendants, child)
+			queue = append(queue, child.Name)
+		}
+	}
+	return descendants
+}
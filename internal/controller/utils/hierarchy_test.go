@@ -0,0 +1,65 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"dana.io/hello-world/internal/controller/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Namespace hierarchy", func() {
+	const parentKey = "example.com/parent"
+
+	// child returns a namespace whose parent is set by a label
+	child := func(name, parent string) corev1.Namespace {
+		return corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{parentKey: parent},
+		}}
+	}
+
+	// names returns the names of the namespaces
+	names := func(namespaces []*corev1.Namespace) []string {
+		var names []string
+		for _, namespace := range namespaces {
+			names = append(names, namespace.Name)
+		}
+		return names
+	}
+
+	It("should prefer the parent label over the annotation", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-a",
+			Labels:      map[string]string{parentKey: "tenant-1"},
+			Annotations: map[string]string{parentKey: "tenant-2"},
+		}}
+		Expect(utils.ParentNamespace(namespace, parentKey)).To(Equal("tenant-1"))
+
+		delete(namespace.ObjectMeta.Labels, parentKey)
+		Expect(utils.ParentNamespace(namespace, parentKey)).To(Equal("tenant-2"))
+	})
+
+	It("should list the descendants nearest first", func() {
+		namespaces := []corev1.Namespace{
+			child("team-a-dev", "team-a"),
+			child("team-b", "tenant"),
+			child("team-a", "tenant"),
+			{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}},
+			child("other", "elsewhere"),
+		}
+
+		Expect(names(utils.DescendantNamespaces(namespaces, "tenant", parentKey))).To(Equal([]string{"team-a", "team-b", "team-a-dev"}))
+		Expect(utils.DescendantNamespaces(namespaces, "team-b", parentKey)).To(BeEmpty())
+	})
+
+	It("should walk a cycle once", func() {
+		namespaces := []corev1.Namespace{
+			child("first", "second"),
+			child("second", "first"),
+		}
+
+		Expect(names(utils.DescendantNamespaces(namespaces, "first", parentKey))).To(Equal([]string{"second"}))
+	})
+})
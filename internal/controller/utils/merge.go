@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"sort"
+	"time"
+)
+
+// LabelClaim holds the labels a single owner wants on a namespace.
+type LabelClaim struct {
+	Owner  string
+	Labels map[string]string
+
+	// Level ranks the claims before their priority, the highest wins. Claims
+	// inherited from the ancestors of a namespace are ranked by their level.
+	Level int
+	// Priority orders the claims competing for a key, the highest wins.
+	Priority int32
+	// Created breaks priority ties, the oldest claim wins.
+	Created time.Time
+	// Reject claims never win a key another claim sets to a different value.
+	Reject bool
+	// Unenforced claims leave their keys as they are on the namespace, rather
+	// than reverting changes made to them.
+	Unenforced bool
+}
+
+// Conflict describes a key an owner lost to another owner.
+type Conflict struct {
+	Key   string
+	Value string
+
+	// Winner is empty when no claim won the key.
+	Winner      string
+	WinnerValue string
+}
+
+// MergeResult is the outcome of merging the claims of all owners in a namespace.
+type MergeResult struct {
+	// Labels are the labels to apply to the namespace.
+	Labels map[string]string
+	// Owners are the owners of every key in Labels.
+	Owners Owners
+	// Conflicts are the keys each owner lost.
+	Conflicts map[string][]Conflict
+	// Unenforced are the keys won only by unenforced claims, they are not
+	// applied to the namespace.
+	Unenforced map[string]string
+	// UnenforcedOwners are the owners of every key in Unenforced.
+	UnenforcedOwners Owners
+}
+
+// Utility function to merge the claims of all owners in a namespace, a key set
+// to different values is won by the highest ranked claim that does not reject conflicts
+func MergeLabels(claims []LabelClaim) MergeResult {
+	ranked := make([]LabelClaim, len(claims))
+	copy(ranked, claims)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Level != ranked[j].Level {
+			return ranked[i].Level > ranked[j].Level
+		}
+		if ranked[i].Priority != ranked[j].Priority {
+			return ranked[i].Priority > ranked[j].Priority
+		}
+		if !ranked[i].Created.Equal(ranked[j].Created) {
+			return ranked[i].Created.Before(ranked[j].Created)
+		}
+		return ranked[i].Owner < ranked[j].Owner
+	})
+
+	// Collect the claims of every key in rank order
+	candidates := make(map[string][]LabelClaim)
+	for _, claim := range ranked {
+		for key := range claim.Labels {
+			candidates[key] = append(candidates[key], claim)
+		}
+	}
+
+	result := MergeResult{
+		Labels:           make(map[string]string),
+		Owners:           Owners{},
+		Conflicts:        make(map[string][]Conflict),
+		Unenforced:       make(map[string]string),
+		UnenforcedOwners: Owners{},
+	}
+
+	for key, claims := range candidates {
+		var winner *LabelClaim
+		conflicting := false
+		for i := range claims {
+			if claims[i].Labels[key] != claims[0].Labels[key] {
+				conflicting = true
+			}
+			if winner == nil && !claims[i].Reject {
+				winner = &claims[i]
+			}
+		}
+
+		if !conflicting {
+			winner = &claims[0]
+		}
+
+		// The key is enforced as long as one of the claims setting the winning
+		// value enforces it
+		enforced := false
+		for _, claim := range claims {
+			if winner != nil && claim.Labels[key] == winner.Labels[key] && !claim.Unenforced {
+				enforced = true
+			}
+		}
+
+		for _, claim := range claims {
+			value := claim.Labels[key]
+			if winner != nil && value == winner.Labels[key] {
+				if enforced {
+					result.Owners.Claim(claim.Owner, map[string]string{key: value})
+				} else {
+					result.UnenforcedOwners.Claim(claim.Owner, map[string]string{key: value})
+				}
+				continue
+			}
+
+			conflict := Conflict{Key: key, Value: value}
+			if winner != nil {
+				conflict.Winner = winner.Owner
+				conflict.WinnerValue = winner.Labels[key]
+			}
+			result.Conflicts[claim.Owner] = append(result.Conflicts[claim.Owner], conflict)
+		}
+
+		if winner != nil && enforced {
+			result.Labels[key] = winner.Labels[key]
+		} else if winner != nil {
+			result.Unenforced[key] = winner.Labels[key]
+		}
+	}
+
+	for owner := range result.Conflicts {
+		sort.Slice(result.Conflicts[owner], func(i, j int) bool {
+			return result.Conflicts[owner][i].Key < result.Conflicts[owner][j].Key
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,89 @@
+package utils_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+var _ = Describe("MergeLabels", func() {
+	older := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	It("should let the oldest claim win by default", func() {
+		merged := utils.MergeLabels([]utils.LabelClaim{
+			{Owner: "second", Labels: map[string]string{"name": "two", "shared": "same"}, Created: newer},
+			{Owner: "first", Labels: map[string]string{"name": "one", "shared": "same"}, Created: older},
+		})
+
+		Expect(merged.Labels).To(Equal(map[string]string{"name": "one", "shared": "same"}))
+		Expect(merged.Owners).To(HaveKeyWithValue("name", []string{"first"}))
+		Expect(merged.Owners).To(HaveKeyWithValue("shared", []string{"first", "second"}))
+		Expect(merged.Conflicts).NotTo(HaveKey("first"))
+		Expect(merged.Conflicts["second"]).To(ConsistOf(utils.Conflict{
+			Key: "name", Value: "two", Winner: "first", WinnerValue: "one",
+		}))
+	})
+
+	It("should let the highest priority win", func() {
+		merged := utils.MergeLabels([]utils.LabelClaim{
+			{Owner: "first", Labels: map[string]string{"name": "one"}, Created: older},
+			{Owner: "second", Labels: map[string]string{"name": "two"}, Created: newer, Priority: 10},
+		})
+
+		Expect(merged.Labels).To(HaveKeyWithValue("name", "two"))
+		Expect(merged.Conflicts["first"]).To(HaveLen(1))
+	})
+
+	It("should rank the level before the priority", func() {
+		merged := utils.MergeLabels([]utils.LabelClaim{
+			{Owner: "parent/first", Labels: map[string]string{"name": "one"}, Created: older, Priority: 10, Level: -1},
+			{Owner: "second", Labels: map[string]string{"name": "two"}, Created: newer},
+		})
+
+		Expect(merged.Labels).To(HaveKeyWithValue("name", "two"))
+		Expect(merged.Conflicts["parent/first"]).To(HaveLen(1))
+	})
+
+	It("should never apply a conflicting key to a rejecting claim", func() {
+		merged := utils.MergeLabels([]utils.LabelClaim{
+			{Owner: "first", Labels: map[string]string{"name": "one", "own": "one"}, Created: older, Reject: true},
+			{Owner: "second", Labels: map[string]string{"name": "two"}, Created: newer},
+		})
+
+		Expect(merged.Labels).To(Equal(map[string]string{"name": "two", "own": "one"}))
+		Expect(merged.Conflicts["first"]).To(ConsistOf(utils.Conflict{
+			Key: "name", Value: "one", Winner: "second", WinnerValue: "two",
+		}))
+	})
+
+	It("should not apply a key when every claim rejects the conflict", func() {
+		merged := utils.MergeLabels([]utils.LabelClaim{
+			{Owner: "first", Labels: map[string]string{"name": "one"}, Created: older, Reject: true},
+			{Owner: "second", Labels: map[string]string{"name": "two"}, Created: newer, Reject: true},
+		})
+
+		Expect(merged.Labels).NotTo(HaveKey("name"))
+		Expect(merged.Owners).NotTo(HaveKey("name"))
+		Expect(merged.Conflicts["first"]).To(ConsistOf(utils.Conflict{Key: "name", Value: "one"}))
+		Expect(merged.Conflicts["second"]).To(ConsistOf(utils.Conflict{Key: "name", Value: "two"}))
+	})
+
+	It("should leave the keys won only by unenforced claims out of the labels", func() {
+		merged := utils.MergeLabels([]utils.LabelClaim{
+			{Owner: "first", Labels: map[string]string{"name": "one", "shared": "same"}, Created: older, Unenforced: true},
+			{Owner: "second", Labels: map[string]string{"name": "two", "shared": "same"}, Created: newer},
+		})
+
+		Expect(merged.Labels).To(Equal(map[string]string{"shared": "same"}))
+		Expect(merged.Owners).To(HaveKeyWithValue("shared", []string{"first", "second"}))
+		Expect(merged.Unenforced).To(Equal(map[string]string{"name": "one"}))
+		Expect(merged.UnenforcedOwners).To(HaveKeyWithValue("name", []string{"first"}))
+		Expect(merged.Conflicts["second"]).To(ConsistOf(utils.Conflict{
+			Key: "name", Value: "two", Winner: "first", WinnerValue: "one",
+		}))
+	})
+})
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// OwnersAnnotation records, for every managed label key on a namespace, the
+// NamespaceLabels that claim it.
+const OwnersAnnotation = "namespacelabeler.dana.io/owners"
+
+// clusterOwnerPrefix prefixes the owner name of ClusterNamespaceLabels, so
+// they never collide with the names of NamespaceLabels.
+const clusterOwnerPrefix = "ClusterNamespaceLabel/"
+
+// Owners maps every managed label key on a namespace to the sorted names of
+// the NamespaceLabels that claim it.
+type Owners map[string][]string
+
+// Utility function to read the owners map stored in the given annotation
+func ParseOwners(annotations map[string]string, annotation string) (Owners, error) {
+	owners := Owners{}
+	value, exists := annotations[annotation]
+	if !exists || value == "" {
+		return owners, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &owners); err != nil {
+		return Owners{}, err
+	}
+	return owners, nil
+}
+
+// Utility function to store the owners map in the given annotation, the
+// annotation is removed when no key is owned anymore
+func SetOwners(annotations map[string]string, annotation string, owners Owners) error {
+	if len(owners) == 0 {
+		delete(annotations, annotation)
+		return nil
+	}
+
+	value, err := json.Marshal(owners)
+	if err != nil {
+		return err
+	}
+	annotations[annotation] = string(value)
+	return nil
+}
+
+// ClusterOwner returns the owner name of the given ClusterNamespaceLabel.
+func ClusterOwner(name string) string {
+	return clusterOwnerPrefix + name
+}
+
+// ParseOwner returns the kind, namespace and name of an owner of namespace
+// labels, as recorded in the owners annotation. The namespace is empty for
+// ClusterNamespaceLabels and for the NamespaceLabels of the labelled namespace,
+// and set for the NamespaceLabels of its ancestors.
+func ParseOwner(owner string) (kind, namespace, name string) {
+	if name, found := strings.CutPrefix(owner, clusterOwnerPrefix); found {
+		return "ClusterNamespaceLabel", "", name
+	}
+	if namespace, name, found := strings.Cut(owner, "/"); found {
+		return "NamespaceLabel", namespace, name
+	}
+	return "NamespaceLabel", "", owner
+}
+
+// KeysOf returns the keys claimed by the given owner.
+func (o Owners) KeysOf(owner string) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for key, names := range o {
+		if contains(names, owner) {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// Claim records the given owner on each of the keys.
+func (o Owners) Claim(owner string, keys map[string]string) {
+	for key := range keys {
+		if contains(o[key], owner) {
+			continue
+		}
+		o[key] = append(o[key], owner)
+		sort.Strings(o[key])
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
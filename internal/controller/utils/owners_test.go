@@ -0,0 +1,40 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+var _ = Describe("Owners", func() {
+	const ownersAnnotation = "namespacelabeler.dana.io/owners"
+
+	It("should return the keys claimed by an owner", func() {
+		owners := utils.Owners{}
+		owners.Claim("first", map[string]string{"shared": "one", "only-first": "one"})
+		owners.Claim("second", map[string]string{"shared": "two"})
+
+		Expect(owners.KeysOf("first")).To(SatisfyAll(HaveKey("shared"), HaveKey("only-first")))
+		Expect(owners.KeysOf("second")).To(SatisfyAll(HaveKey("shared"), Not(HaveKey("only-first"))))
+		Expect(owners).To(HaveKeyWithValue("shared", []string{"first", "second"}))
+	})
+
+	It("should round trip through the namespace annotations", func() {
+		annotations := map[string]string{"foreign": "value"}
+		owners := utils.Owners{}
+		owners.Claim("second", map[string]string{"shared": "two"})
+		owners.Claim("first", map[string]string{"shared": "one"})
+
+		Expect(utils.SetOwners(annotations, ownersAnnotation, owners)).To(Succeed())
+		Expect(annotations).To(HaveKeyWithValue(ownersAnnotation, `{"shared":["first","second"]}`))
+
+		parsed, err := utils.ParseOwners(annotations, ownersAnnotation)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(owners))
+
+		Expect(utils.SetOwners(annotations, ownersAnnotation, utils.Owners{})).To(Succeed())
+		Expect(annotations).NotTo(HaveKey(ownersAnnotation))
+		Expect(annotations).To(HaveKeyWithValue("foreign", "value"))
+	})
+})
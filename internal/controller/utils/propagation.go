@@ -0,0 +1,89 @@
+package utils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+)
+
+// PropagatedLabels returns the labels of the namespace the NamespaceLabels in
+// it propagate to the objects of the given kind, and the NamespaceLabels
+// propagating every key. Only the labels a NamespaceLabel won on the namespace
+// are propagated, with the value applied to the namespace.
+func PropagatedLabels(namespace *corev1.Namespace, namespaceLabels []danaiodanaiov1alpha1.NamespaceLabel, kind danaiodanaiov1alpha1.PropagationKind) (map[string]string, Owners, error) {
+	owners, err := ParseOwners(namespace.ObjectMeta.Annotations, OwnersAnnotation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labels := make(map[string]string)
+	propagated := Owners{}
+	for i := range namespaceLabels {
+		namespaceLabel := &namespaceLabels[i]
+		if !namespaceLabel.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, propagation := range namespaceLabel.Spec.PropagateTo {
+			if propagation.Kind != kind {
+				continue
+			}
+			for key := range owners.KeysOf(namespaceLabel.Name) {
+				value, exists := namespace.ObjectMeta.Labels[key]
+				if !exists || (len(propagation.Keys) > 0 && !contains(propagation.Keys, key)) {
+					continue
+				}
+				labels[key] = value
+				propagated.Claim(namespaceLabel.Name, map[string]string{key: value})
+			}
+		}
+	}
+	return labels, propagated, nil
+}
+
+// SetPropagatedLabels sets the propagated labels on the metadata of an object,
+// and removes the labels propagated before that are not anymore. The owners
+// of the propagated labels are recorded on the object like on the namespace,
+// the labels they never propagated are left alone: a key the object already
+// sets is never overwritten.
+func SetPropagatedLabels(object metav1.Object, labels map[string]string, owners Owners) error {
+	previous, err := ParseOwners(object.GetAnnotations(), OwnersAnnotation)
+	if err != nil {
+		return err
+	}
+
+	objectLabels := object.GetLabels()
+	if objectLabels == nil {
+		objectLabels = make(map[string]string)
+	}
+	for key := range previous {
+		if _, exists := labels[key]; !exists {
+			delete(objectLabels, key)
+		}
+	}
+	propagated := Owners{}
+	for key, value := range labels {
+		if _, exists := objectLabels[key]; exists && len(previous[key]) == 0 {
+			continue
+		}
+		objectLabels[key] = value
+		propagated[key] = owners[key]
+	}
+	if len(objectLabels) == 0 {
+		objectLabels = nil
+	}
+	object.SetLabels(objectLabels)
+
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if err := SetOwners(annotations, OwnersAnnotation, propagated); err != nil {
+		return err
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	object.SetAnnotations(annotations)
+	return nil
+}
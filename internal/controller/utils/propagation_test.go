@@ -0,0 +1,54 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+var _ = Describe("Propagation", func() {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "apps",
+			Labels:      map[string]string{"team": "payments", "tier": "gold", "manual": "value"},
+			Annotations: map[string]string{utils.OwnersAnnotation: `{"team":["web"],"tier":["web"]}`},
+		},
+	}
+	namespaceLabels := []danaiodanaiov1alpha1.NamespaceLabel{{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+		Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+			PropagateTo: []danaiodanaiov1alpha1.LabelPropagation{
+				{Kind: danaiodanaiov1alpha1.PodsPropagationKind, Keys: []string{"team"}},
+			},
+		},
+	}}
+
+	It("should propagate the selected keys the NamespaceLabels won", func() {
+		labels, owners, err := utils.PropagatedLabels(namespace, namespaceLabels, danaiodanaiov1alpha1.PodsPropagationKind)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(Equal(map[string]string{"team": "payments"}))
+		Expect(owners).To(Equal(utils.Owners{"team": []string{"web"}}))
+
+		labels, _, err = utils.PropagatedLabels(namespace, namespaceLabels, danaiodanaiov1alpha1.ServicesPropagationKind)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(BeEmpty())
+	})
+
+	It("should keep the labels the object already sets", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "own"}}}
+
+		Expect(utils.SetPropagatedLabels(pod, map[string]string{"team": "payments", "tier": "gold"},
+			utils.Owners{"team": []string{"web"}, "tier": []string{"web"}})).To(Succeed())
+		Expect(pod.Labels).To(Equal(map[string]string{"team": "own", "tier": "gold"}))
+		Expect(pod.Annotations).To(HaveKeyWithValue(utils.OwnersAnnotation, `{"tier":["web"]}`))
+
+		Expect(utils.SetPropagatedLabels(pod, nil, nil)).To(Succeed())
+		Expect(pod.Labels).To(Equal(map[string]string{"team": "own"}))
+		Expect(pod.Annotations).To(BeEmpty())
+	})
+})
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a recurring window, opened at every activation of a cron
+// expression and closed after a fixed duration.
+type Schedule struct {
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+// ParseSchedule parses a standard cron expression evaluated in the given time
+// zone, UTC when empty.
+func ParseSchedule(expression string, duration time.Duration, timeZone string) (Schedule, error) {
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if duration <= 0 {
+		return Schedule{}, fmt.Errorf("the duration must be positive")
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return Schedule{}, err
+	}
+	return Schedule{schedule: schedule, duration: duration, location: location}, nil
+}
+
+// Window returns the window of the schedule the time falls in, and otherwise
+// the start of the next window. Overlapping windows are reported as one that
+// ends with the last of them. Start is zero outside of a window, and next is
+// zero when the schedule never opens again.
+func (s Schedule) Window(now time.Time) (start, end, next time.Time) {
+	local := now.In(s.location)
+
+	// the windows still open were started within the last duration
+	for t := s.schedule.Next(local.Add(-s.duration)); !t.IsZero() && !t.After(local); t = s.schedule.Next(t) {
+		if start.IsZero() {
+			start = t
+		}
+		end = t.Add(s.duration)
+	}
+	if start.IsZero() {
+		return time.Time{}, time.Time{}, s.schedule.Next(local)
+	}
+	return start, end, end
+}
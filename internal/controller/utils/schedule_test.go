@@ -0,0 +1,60 @@
+package utils_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+var _ = Describe("Schedule", func() {
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	// the deploy freeze from Friday 18:00 to Monday 06:00, Paris time
+	freeze, err := utils.ParseSchedule("0 18 * * 5", 60*time.Hour, "Europe/Paris")
+
+	It("should parse the schedule", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report the window the time falls in", func() {
+		start, end, next := freeze.Window(time.Date(2023, time.July, 16, 12, 0, 0, 0, paris))
+
+		Expect(start).To(BeTemporally("==", time.Date(2023, time.July, 14, 18, 0, 0, 0, paris)))
+		Expect(end).To(BeTemporally("==", time.Date(2023, time.July, 17, 6, 0, 0, 0, paris)))
+		Expect(next).To(BeTemporally("==", end))
+	})
+
+	It("should report the start of the next window outside of a window", func() {
+		start, _, next := freeze.Window(time.Date(2023, time.July, 17, 6, 0, 0, 0, paris))
+
+		Expect(start).To(BeZero())
+		Expect(next).To(BeTemporally("==", time.Date(2023, time.July, 21, 18, 0, 0, 0, paris)))
+	})
+
+	It("should evaluate the cron expression in the time zone", func() {
+		start, _, _ := freeze.Window(time.Date(2023, time.July, 14, 16, 30, 0, 0, time.UTC))
+
+		Expect(start).To(BeTemporally("==", time.Date(2023, time.July, 14, 16, 0, 0, 0, time.UTC)))
+	})
+
+	It("should merge overlapping windows", func() {
+		hourly, err := utils.ParseSchedule("0 * * * *", 90*time.Minute, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		start, end, _ := hourly.Window(time.Date(2023, time.July, 14, 10, 45, 0, 0, time.UTC))
+		Expect(start).To(BeTemporally("==", time.Date(2023, time.July, 14, 10, 0, 0, 0, time.UTC)))
+		Expect(end).To(BeTemporally("==", time.Date(2023, time.July, 14, 11, 30, 0, 0, time.UTC)))
+	})
+
+	It("should reject invalid schedules", func() {
+		_, err := utils.ParseSchedule("every friday", time.Hour, "")
+		Expect(err).To(HaveOccurred())
+		_, err = utils.ParseSchedule("0 18 * * 5", 0, "")
+		Expect(err).To(HaveOccurred())
+		_, err = utils.ParseSchedule("0 18 * * 5", time.Hour, "Mars/Olympus")
+		Expect(err).To(HaveOccurred())
+	})
+})
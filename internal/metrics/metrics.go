@@ -0,0 +1,86 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics holds the Prometheus metrics of the operator, they are
+// served with the controller-runtime metrics on the metrics endpoint. The
+// namespace a metric is about is its target_namespace label, since Prometheus
+// sets the namespace label to the namespace of the operator when scraping it.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// LabelDrift counts the labels found drifted from a NamespaceLabel using
+	// the Report enforcement.
+	LabelDrift = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "namespacelabel_label_drift_total",
+		Help: "Number of labels found drifted from a NamespaceLabel using the Report enforcement",
+	}, []string{"target_namespace", "namespacelabel"})
+
+	// ManagedLabels is the number of labels the operator manages on every namespace.
+	ManagedLabels = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "namespacelabel_managed_labels",
+		Help: "Number of labels managed by the operator on the namespace",
+	}, []string{"target_namespace"})
+
+	// Conflicts counts the conflicts detected between the owners of the labels of a namespace.
+	Conflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "namespacelabel_conflicts_total",
+		Help: "Number of label conflicts detected between NamespaceLabels and ClusterNamespaceLabels",
+	}, []string{"target_namespace"})
+
+	// DriftReverts counts the changes made to managed labels that the operator reverted.
+	DriftReverts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "namespacelabel_drift_reverts_total",
+		Help: "Number of changes made to managed labels that were reverted",
+	}, []string{"target_namespace"})
+
+	// WebhookDenials counts the requests denied by the webhooks.
+	WebhookDenials = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "namespacelabel_webhook_denials_total",
+		Help: "Number of requests denied by the webhooks",
+	}, []string{"webhook", "reason"})
+
+	// NamespaceWriteDuration is the latency of the writes to namespaces.
+	NamespaceWriteDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "namespacelabel_namespace_write_duration_seconds",
+		Help:    "Latency of the writes of labels to namespaces",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+)
+
+// WebhookDenialRecorder records the requests denied by the webhooks of the
+// API in WebhookDenials, the reasons are the Denial constants of the API.
+type WebhookDenialRecorder struct{}
+
+// RecordDenial counts a request denied by the webhook for the reason.
+func (WebhookDenialRecorder) RecordDenial(webhook, reason string) {
+	WebhookDenials.WithLabelValues(webhook, reason).Inc()
+}
+
+func init() {
+	metrics.Registry.MustRegister(
+		LabelDrift,
+		ManagedLabels,
+		Conflicts,
+		DriftReverts,
+		WebhookDenials,
+		NamespaceWriteDuration,
+	)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+	"dana.io/hello-world/internal/metrics"
+)
+
+// ProtectManagedLabelsLabel opts a namespace in to the protection of the labels
+// managed by the operator, when set to "true".
+const ProtectManagedLabelsLabel = "namespacelabel.dana.io/protect-managed-labels"
+
+// log is for logging in this package.
+var namespacelog = logf.Log.WithName("namespace-resource")
+
+// SetupNamespaceWebhookWithManager registers the Namespace webhooks with the manager.
+func SetupNamespaceWebhookWithManager(mgr ctrl.Manager, validator *NamespaceValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Namespace{}).
+		WithValidator(validator).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate--v1-namespace,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=namespaces,verbs=update,versions=v1,name=vnamespace.kb.io,admissionReviewVersions=v1
+
+// NamespaceValidator denies manual changes to the labels the operator manages,
+// so they are not silently reverted by the next reconciliation. It fails open,
+// the next reconciliation reverts the changes it could not deny.
+type NamespaceValidator struct {
+	// OperatorUsername is the username of the operator, the only one allowed
+	// to change the managed labels.
+	OperatorUsername string
+
+	// ProtectAllNamespaces protects the managed labels of every namespace,
+	// rather than only the namespaces labeled with ProtectManagedLabelsLabel.
+	ProtectAllNamespaces bool
+}
+
+var _ crwebhook.CustomValidator = &NamespaceValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type,
+// new namespaces have no managed labels yet. Their required labels are checked
+// by the RequiredLabelsValidator.
+func (v *NamespaceValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator to deny changes to the
+// managed labels of a namespace by anyone but the operator.
+func (v *NamespaceValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	old, ok := oldObj.(*corev1.Namespace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Namespace but got a %T", oldObj)
+	}
+	namespace, ok := newObj.(*corev1.Namespace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Namespace but got a %T", newObj)
+	}
+
+	if !v.ProtectAllNamespaces && old.ObjectMeta.Labels[ProtectManagedLabelsLabel] != "true" {
+		return nil, nil
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserInfo.Username == v.OperatorUsername {
+		return nil, nil
+	}
+
+	owners, err := utils.ParseOwners(old.ObjectMeta.Annotations, utils.OwnersAnnotation)
+	if err != nil {
+		// the annotation is written by the operator only, there is nothing to
+		// protect if it can't be read
+		namespacelog.Error(err, "unable to parse the owners annotation", "name", old.Name)
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(owners))
+	for key := range owners {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("metadata", "labels")
+	for _, key := range keys {
+		oldValue, oldExists := old.ObjectMeta.Labels[key]
+		value, exists := namespace.ObjectMeta.Labels[key]
+		if oldExists == exists && oldValue == value {
+			continue
+		}
+		allErrs = append(allErrs, field.Forbidden(fldPath.Key(key),
+			fmt.Sprintf("the label is managed by %s, change it there instead", describeOwners(owners[key], old.Name))))
+	}
+
+	if len(allErrs) == 0 {
+		return nil, nil
+	}
+
+	namespacelog.Info("denied a change to managed labels", "name", old.Name, "username", req.UserInfo.Username)
+	metrics.WebhookDenials.WithLabelValues("namespace", danaiodanaiov1alpha1.DenialManagedLabel).Inc()
+	return nil, apierrors.NewInvalid(corev1.SchemeGroupVersion.WithKind("Namespace").GroupKind(), old.Name, allErrs)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *NamespaceValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// describeOwners names the owners of a label key, e.g.
+// NamespaceLabel "team" in namespace "apps" and ClusterNamespaceLabel "platform".
+func describeOwners(owners []string, namespace string) string {
+	descriptions := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		kind, ownerNamespace, name := utils.ParseOwner(owner)
+		if kind == "NamespaceLabel" {
+			if ownerNamespace == "" {
+				ownerNamespace = namespace
+			}
+			descriptions = append(descriptions, fmt.Sprintf("%s %q in namespace %q", kind, name, ownerNamespace))
+		} else {
+			descriptions = append(descriptions, fmt.Sprintf("%s %q", kind, name))
+		}
+	}
+	return strings.Join(descriptions, " and ")
+}
@@ -0,0 +1,121 @@
+package webhook_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+	"dana.io/hello-world/internal/metrics"
+	"dana.io/hello-world/internal/webhook"
+)
+
+func TestWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhook Suite")
+}
+
+var _ = Describe("NamespaceValidator", func() {
+	const operatorUsername = "system:serviceaccount:hello-world-system:hello-world-controller-manager"
+
+	var validator *webhook.NamespaceValidator
+	var old *corev1.Namespace
+
+	// requestBy returns a context of an admission request made by the given user
+	requestBy := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				UserInfo:  authenticationv1.UserInfo{Username: username},
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		validator = &webhook.NamespaceValidator{OperatorUsername: operatorUsername}
+		old = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "apps",
+				Labels: map[string]string{
+					webhook.ProtectManagedLabelsLabel: "true",
+					"team":                            "apps",
+					"unmanaged":                       "value",
+				},
+				Annotations: map[string]string{
+					utils.OwnersAnnotation: `{"team":["ClusterNamespaceLabel/platform","team-labels"]}`,
+				},
+			},
+		}
+	})
+
+	It("should deny users changing a managed label, naming its owners", func() {
+		namespace := old.DeepCopy()
+		namespace.Labels["team"] = "other"
+		denials := metrics.WebhookDenials.WithLabelValues("namespace", danaiodanaiov1alpha1.DenialManagedLabel)
+		denied := testutil.ToFloat64(denials)
+
+		_, err := validator.ValidateUpdate(requestBy("jane"), old, namespace)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(testutil.ToFloat64(denials)).To(Equal(denied + 1))
+		Expect(err.Error()).To(ContainSubstring(`NamespaceLabel "team-labels" in namespace "apps"`))
+		Expect(err.Error()).To(ContainSubstring(`ClusterNamespaceLabel "platform"`))
+	})
+
+	It("should name the ancestor of an inherited label", func() {
+		old.Annotations[utils.OwnersAnnotation] = `{"team":["tenant/tenant-labels"]}`
+		namespace := old.DeepCopy()
+		namespace.Labels["team"] = "other"
+
+		_, err := validator.ValidateUpdate(requestBy("jane"), old, namespace)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`NamespaceLabel "tenant-labels" in namespace "tenant"`))
+	})
+
+	It("should deny users removing a managed label", func() {
+		namespace := old.DeepCopy()
+		delete(namespace.Labels, "team")
+
+		_, err := validator.ValidateUpdate(requestBy("jane"), old, namespace)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("should allow users changing unmanaged labels", func() {
+		namespace := old.DeepCopy()
+		namespace.Labels["unmanaged"] = "other"
+
+		_, err := validator.ValidateUpdate(requestBy("jane"), old, namespace)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should allow the operator changing a managed label", func() {
+		namespace := old.DeepCopy()
+		namespace.Labels["team"] = "other"
+
+		_, err := validator.ValidateUpdate(requestBy(operatorUsername), old, namespace)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only protect namespaces that opted in", func() {
+		delete(old.Labels, webhook.ProtectManagedLabelsLabel)
+		namespace := old.DeepCopy()
+		namespace.Labels["team"] = "other"
+
+		_, err := validator.ValidateUpdate(requestBy("jane"), old, namespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		validator.ProtectAllNamespaces = true
+		_, err = validator.ValidateUpdate(requestBy("jane"), old, namespace)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,109 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+)
+
+// log is for logging in this package.
+var podlog = logf.Log.WithName("pod-resource")
+
+// SetupPodWebhookWithManager registers the Pod webhooks with the manager.
+func SetupPodWebhookWithManager(mgr ctrl.Manager, labeler *PodLabeler) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Pod{}).
+		WithDefaulter(labeler).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.kb.io,admissionReviewVersions=v1
+
+// PodLabeler labels new Pods with the labels the NamespaceLabels of their
+// namespace propagate to Pods, the Pods created before are labelled by the
+// reconciliation. The labels the Pods already set are never overwritten.
+// The webhook skips the system namespaces, see config/webhook/pod_webhook_scope_patch.yaml.
+type PodLabeler struct {
+	// Client reads the NamespaceLabels and the namespaces, it should be the
+	// cached client of the manager as it is called for every new Pod.
+	Client client.Reader
+}
+
+var _ crwebhook.CustomDefaulter = &PodLabeler{}
+
+// Default implements webhook.CustomDefaulter. Pods are never denied, they are
+// admitted without the labels when these can't be read.
+func (l *PodLabeler) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod but got a %T", obj)
+	}
+
+	// the namespace of a Pod is only set by the request when it is created by
+	// a controller
+	namespaceName := pod.Namespace
+	if namespaceName == "" {
+		req, err := admission.RequestFromContext(ctx)
+		if err != nil {
+			return err
+		}
+		namespaceName = req.Namespace
+	}
+
+	var namespaceLabelList danaiodanaiov1alpha1.NamespaceLabelList
+	if err := l.Client.List(ctx, &namespaceLabelList, client.InNamespace(namespaceName)); err != nil {
+		podlog.Error(err, "unable to list the NamespaceLabels", "namespace", namespaceName)
+		return nil
+	}
+	propagating := false
+	for _, namespaceLabel := range namespaceLabelList.Items {
+		for _, propagation := range namespaceLabel.Spec.PropagateTo {
+			propagating = propagating || propagation.Kind == danaiodanaiov1alpha1.PodsPropagationKind
+		}
+	}
+	if !propagating {
+		return nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := l.Client.Get(ctx, types.NamespacedName{Name: namespaceName}, namespace); err != nil {
+		podlog.Error(err, "unable to get the namespace", "namespace", namespaceName)
+		return nil
+	}
+	labels, owners, err := utils.PropagatedLabels(namespace, namespaceLabelList.Items, danaiodanaiov1alpha1.PodsPropagationKind)
+	if err != nil {
+		podlog.Error(err, "unable to read the propagated labels", "namespace", namespaceName)
+		return nil
+	}
+	if err := utils.SetPropagatedLabels(pod, labels, owners); err != nil {
+		podlog.Error(err, "unable to set the propagated labels", "namespace", namespaceName)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package webhook_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/controller/utils"
+	"dana.io/hello-world/internal/webhook"
+)
+
+var _ = Describe("PodLabeler", func() {
+	var labeler *webhook.PodLabeler
+	var namespaceLabel *danaiodanaiov1alpha1.NamespaceLabel
+
+	// createIn returns a context of an admission request creating a Pod in the namespace
+	createIn := func(namespace string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Namespace: namespace,
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "apps",
+				Labels:      map[string]string{"team": "apps", "cost-center": "1234", "unmanaged": "value"},
+				Annotations: map[string]string{utils.OwnersAnnotation: `{"cost-center":["team-labels"],"team":["team-labels"]}`},
+			},
+		}
+		namespaceLabel = &danaiodanaiov1alpha1.NamespaceLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-labels", Namespace: "apps"},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelSpec{
+				Labels: map[string]string{"team": "apps", "cost-center": "1234"},
+				PropagateTo: []danaiodanaiov1alpha1.LabelPropagation{
+					{Kind: danaiodanaiov1alpha1.PodsPropagationKind, Keys: []string{"cost-center"}},
+				},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(danaiodanaiov1alpha1.AddToScheme(scheme)).To(Succeed())
+		labeler = &webhook.PodLabeler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, namespaceLabel).Build(),
+		}
+	})
+
+	It("should label new Pods with the selected managed labels", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "web-",
+			Labels:       map[string]string{"app": "web"},
+		}}
+
+		Expect(labeler.Default(createIn("apps"), pod)).To(Succeed())
+		Expect(pod.Labels).To(Equal(map[string]string{"app": "web", "cost-center": "1234"}))
+		Expect(pod.Annotations).To(HaveKeyWithValue(utils.OwnersAnnotation, `{"cost-center":["team-labels"]}`))
+	})
+
+	It("should not overwrite the labels Pods already set", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "web-",
+			Labels:       map[string]string{"app": "web", "cost-center": "5678"},
+		}}
+
+		Expect(labeler.Default(createIn("apps"), pod)).To(Succeed())
+		Expect(pod.Labels).To(Equal(map[string]string{"app": "web", "cost-center": "5678"}))
+		Expect(pod.Annotations).NotTo(HaveKey(utils.OwnersAnnotation))
+	})
+
+	It("should leave Pods alone in namespaces propagating nothing to Pods", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "other"}}
+
+		Expect(labeler.Default(createIn("other"), pod)).To(Succeed())
+		Expect(pod.Labels).To(BeEmpty())
+		Expect(pod.Annotations).To(BeEmpty())
+	})
+})
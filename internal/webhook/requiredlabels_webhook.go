@@ -0,0 +1,124 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/metrics"
+)
+
+// RequiredLabelsPath is the path of the webhook checking the required labels
+// of new namespaces, the Namespace webhooks of the builder have their own path.
+const RequiredLabelsPath = "/validate-required-labels--v1-namespace"
+
+// SetupRequiredLabelsWebhookWithManager registers the webhook checking the
+// required labels of new namespaces with the manager.
+func SetupRequiredLabelsWebhookWithManager(mgr ctrl.Manager, validator *RequiredLabelsValidator) error {
+	mgr.GetWebhookServer().Register(RequiredLabelsPath, admission.WithCustomValidator(mgr.GetScheme(), &corev1.Namespace{}, validator))
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-required-labels--v1-namespace,mutating=false,failurePolicy=fail,sideEffects=None,groups="",resources=namespaces,verbs=create,versions=v1,name=vnamespacerequiredlabels.kb.io,admissionReviewVersions=v1
+
+// RequiredLabelsValidator denies new namespaces missing the labels required by
+// a NamespaceLabelPolicy with the Deny mode. It fails closed so the Deny mode
+// is enforced, the system namespaces and the namespace of the operator are left
+// out of it by config/webhook/namespace_webhook_scope_patch.yaml so they can
+// always be created.
+type RequiredLabelsValidator struct {
+	// Client lists the NamespaceLabelPolicies.
+	Client client.Reader
+}
+
+var _ crwebhook.CustomValidator = &RequiredLabelsValidator{}
+
+// ValidateCreate implements webhook.CustomValidator to deny new namespaces
+// missing required labels. The missing labels of the policies with the Default
+// mode are only warned about, they are set by the operator once the namespace
+// is created.
+func (v *RequiredLabelsValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Namespace but got a %T", obj)
+	}
+
+	// the namespace is denied when the policies can't be read
+	var policyList danaiodanaiov1alpha1.NamespaceLabelPolicyList
+	if err := v.Client.List(ctx, &policyList); err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("unable to list the NamespaceLabelPolicies: %w", err))
+	}
+
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("metadata", "labels")
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+		missing := policy.MissingNamespaceLabels(namespace)
+		if len(missing) == 0 {
+			continue
+		}
+		selected, err := policy.Selects(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			continue
+		}
+		if policy.Spec.Namespaces.Mode == danaiodanaiov1alpha1.DefaultNamespaceAdmission {
+			warnings = append(warnings, fmt.Sprintf("labels %s are required by NamespaceLabelPolicy %q and will be set to their default values",
+				strings.Join(missing, ", "), policy.Name))
+			continue
+		}
+		for _, key := range missing {
+			allErrs = append(allErrs, field.Required(fldPath.Key(key),
+				fmt.Sprintf("the label is required by NamespaceLabelPolicy %q", policy.Name)))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+
+	namespacelog.Info("denied a namespace missing required labels", "name", namespace.Name)
+	metrics.WebhookDenials.WithLabelValues("namespace", danaiodanaiov1alpha1.DenialPolicy).Inc()
+	return warnings, apierrors.NewInvalid(corev1.SchemeGroupVersion.WithKind("Namespace").GroupKind(), namespace.Name, allErrs)
+}
+
+// ValidateUpdate implements webhook.CustomValidator, the required labels are
+// only checked on creation.
+func (v *RequiredLabelsValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.CustomValidator, the required labels are
+// only checked on creation.
+func (v *RequiredLabelsValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,89 @@
+package webhook_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	danaiodanaiov1alpha1 "dana.io/hello-world/api/v1alpha1"
+	"dana.io/hello-world/internal/metrics"
+	"dana.io/hello-world/internal/webhook"
+)
+
+var _ = Describe("RequiredLabelsValidator", func() {
+	var validator *webhook.RequiredLabelsValidator
+	var denyPolicy, defaultPolicy *danaiodanaiov1alpha1.NamespaceLabelPolicy
+
+	BeforeEach(func() {
+		denyPolicy = &danaiodanaiov1alpha1.NamespaceLabelPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "teams"},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelPolicySpec{
+				NamespaceSelector: danaiodanaiov1alpha1.NamespaceSelector{Names: []string{"team-*"}},
+				Namespaces: &danaiodanaiov1alpha1.NamespaceRequirements{
+					RequiredKeys: []string{"team", "cost-center"},
+					Mode:         danaiodanaiov1alpha1.DenyNamespaceAdmission,
+				},
+			},
+		}
+		defaultPolicy = &danaiodanaiov1alpha1.NamespaceLabelPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "environments"},
+			Spec: danaiodanaiov1alpha1.NamespaceLabelPolicySpec{
+				Namespaces: &danaiodanaiov1alpha1.NamespaceRequirements{
+					RequiredKeys: []string{"env"},
+					Mode:         danaiodanaiov1alpha1.DefaultNamespaceAdmission,
+					Defaults:     map[string]string{"env": "unknown"},
+				},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(danaiodanaiov1alpha1.AddToScheme(scheme)).To(Succeed())
+		validator = &webhook.RequiredLabelsValidator{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(denyPolicy, defaultPolicy).Build(),
+		}
+	})
+
+	It("should deny namespaces missing the labels required with the Deny mode", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-a",
+			Labels: map[string]string{"team": "a", "env": "dev"},
+		}}
+		denials := metrics.WebhookDenials.WithLabelValues("namespace", danaiodanaiov1alpha1.DenialPolicy)
+		denied := testutil.ToFloat64(denials)
+
+		_, err := validator.ValidateCreate(context.Background(), namespace)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(SatisfyAll(ContainSubstring("metadata.labels[cost-center]"), Not(ContainSubstring("metadata.labels[team]"))))
+		Expect(testutil.ToFloat64(denials)).To(Equal(denied + 1))
+
+		namespace.Labels["cost-center"] = "42"
+		_, err = validator.ValidateCreate(context.Background(), namespace)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only warn about the labels required with the Default mode", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}}
+
+		warnings, err := validator.ValidateCreate(context.Background(), namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(ContainSubstring(`labels env are required by NamespaceLabelPolicy "environments"`)))
+	})
+
+	It("should deny namespaces when the policies can't be read", func() {
+		// the NamespaceLabelPolicies are not known to the client
+		validator.Client = fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+
+		_, err := validator.ValidateCreate(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}})
+		Expect(apierrors.IsInternalError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,137 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package template renders label values from the metadata of the namespace
+// they are applied to, e.g. "{{ .Namespace.Name }}" or
+// "{{ .Namespace.CreationTimestamp | date "2006-01" }}".
+package template
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Namespace is the metadata of the namespace available to the templates.
+type Namespace struct {
+	Name              string
+	Labels            map[string]string
+	Annotations       map[string]string
+	CreationTimestamp time.Time
+}
+
+// Data is the data the templates are rendered with.
+type Data struct {
+	Namespace Namespace
+}
+
+// NamespaceData returns the data to render the templates of the namespace with.
+func NamespaceData(namespace *corev1.Namespace) Data {
+	return Data{
+		Namespace: Namespace{
+			Name:              namespace.Name,
+			Labels:            namespace.ObjectMeta.Labels,
+			Annotations:       namespace.ObjectMeta.Annotations,
+			CreationTimestamp: namespace.CreationTimestamp.Time,
+		},
+	}
+}
+
+// exampleData is used to check that a template can be rendered.
+var exampleData = Data{
+	Namespace: Namespace{
+		Name:              "example",
+		Labels:            map[string]string{},
+		Annotations:       map[string]string{},
+		CreationTimestamp: time.Unix(0, 0).UTC(),
+	},
+}
+
+var errNotAllowed = errors.New("function is not allowed in label templates")
+
+// funcs is the restricted set of functions of the templates, the builtins
+// that could call arbitrary functions are disabled.
+var funcs = template.FuncMap{
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"trunc": func(length int, s string) (string, error) {
+		if length < 0 {
+			return "", fmt.Errorf("trunc length %d is negative", length)
+		}
+		// truncate by characters, so multi-byte characters are not split
+		if runes := []rune(s); len(runes) > length {
+			return string(runes[:length]), nil
+		}
+		return s, nil
+	},
+	"default": func(fallback, s string) string {
+		if s == "" {
+			return fallback
+		}
+		return s
+	},
+	"call": func(...interface{}) (string, error) {
+		return "", errNotAllowed
+	},
+}
+
+// IsTemplate returns whether the value has to be rendered.
+func IsTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+func parse(value string) (*template.Template, error) {
+	return template.New("label").Funcs(funcs).Option("missingkey=zero").Parse(value)
+}
+
+// Render renders the value with the data, values that are not templates are
+// returned as they are.
+func Render(value string, data Data) (string, error) {
+	if !IsTemplate(value) {
+		return value, nil
+	}
+
+	tmpl, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+// Validate checks that the value can be rendered, by rendering it for an
+// example namespace.
+func Validate(value string) error {
+	if _, err := Render(value, exampleData); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return nil
+}
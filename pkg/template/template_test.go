@@ -0,0 +1,60 @@
+package template_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"dana.io/hello-world/pkg/template"
+)
+
+func TestTemplate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Template Suite")
+}
+
+var _ = Describe("Render", func() {
+	data := template.NamespaceData(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "team-apps",
+			Annotations:       map[string]string{"owner": "Jane"},
+			CreationTimestamp: metav1.NewTime(time.Date(2023, time.July, 14, 0, 0, 0, 0, time.UTC)),
+		},
+	})
+
+	It("should render the metadata of the namespace", func() {
+		for value, rendered := range map[string]string{
+			"{{ .Namespace.Name }}":                                    "team-apps",
+			`{{ index .Namespace.Annotations "owner" | lower }}`:       "jane",
+			`{{ .Namespace.CreationTimestamp | date "2006-01" }}`:      "2023-07",
+			`{{ .Namespace.Name | trimPrefix "team-" }}`:               "apps",
+			`{{ index .Namespace.Labels "missing" | default "none" }}`: "none",
+			"literal": "literal",
+		} {
+			Expect(template.Render(value, data)).To(Equal(rendered), "value %q", value)
+		}
+	})
+
+	It("should truncate by characters", func() {
+		Expect(template.Render(`{{ .Namespace.Name | trunc 4 }}`, data)).To(Equal("team"))
+		Expect(template.Render(`{{ "équipe" | trunc 2 }}`, data)).To(Equal("éq"))
+		Expect(template.Render(`{{ "équipe" | trunc 10 }}`, data)).To(Equal("équipe"))
+
+		_, err := template.Render(`{{ .Namespace.Name | trunc -1 }}`, data)
+		Expect(err).To(MatchError(ContainSubstring("negative")))
+	})
+
+	It("should reject invalid templates", func() {
+		Expect(template.Validate("{{ .Namespace.Name")).NotTo(Succeed())
+		Expect(template.Validate("{{ .Namespace.Unknown }}")).NotTo(Succeed())
+		Expect(template.Validate("{{ call .Namespace.Name }}")).NotTo(Succeed())
+		Expect(template.Validate("{{ env \"HOME\" }}")).NotTo(Succeed())
+		Expect(template.Validate("{{ .Namespace.Name | trunc -1 }}")).NotTo(Succeed())
+		Expect(template.Validate("{{ .Namespace.Name }}")).To(Succeed())
+	})
+})